@@ -1,51 +1,142 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/nikitaenmi/AvitoTest/internal/config"
 	"github.com/nikitaenmi/AvitoTest/internal/database"
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
 	"github.com/nikitaenmi/AvitoTest/internal/handlers"
+	"github.com/nikitaenmi/AvitoTest/internal/health"
+	"github.com/nikitaenmi/AvitoTest/internal/logging"
+	"github.com/nikitaenmi/AvitoTest/internal/metrics"
 	"github.com/nikitaenmi/AvitoTest/internal/repository"
+	"github.com/nikitaenmi/AvitoTest/internal/repository/instrumented"
+	"github.com/nikitaenmi/AvitoTest/internal/repository/memory"
 	"github.com/nikitaenmi/AvitoTest/internal/service"
+	"github.com/nikitaenmi/AvitoTest/internal/tracing"
+	"github.com/nikitaenmi/AvitoTest/migrations"
 	"gorm.io/gorm"
 )
 
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatal("Failed to load config:", err)
+		fatal("failed to load config", err)
 	}
 
-	db, err := connectDatabase(cfg.Database)
+	logger := logging.New(os.Stdout, cfg.Log.Level, cfg.Log.Format)
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if cfg.Storage != config.StoragePostgres {
+			fatal("migrations require STORAGE=postgres", nil)
+		}
+		db, err := connectDatabase(ctx, cfg.Database)
+		if err != nil {
+			fatal("database init failed", err)
+		}
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			fatal("migration failed", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "token" {
+		if cfg.Storage != config.StoragePostgres {
+			fatal("token management requires STORAGE=postgres", nil)
+		}
+		db, err := connectDatabase(ctx, cfg.Database)
+		if err != nil {
+			fatal("database init failed", err)
+		}
+		svc := service.NewService(repository.NewRepositories(db), repository.NewTxManager(db), service.NewLeastLoadedSelector(), service.NewTeamPolicy(), nil)
+		if err := runToken(svc, os.Args[2:]); err != nil {
+			fatal("token command failed", err)
+		}
+		return
+	}
+
+	checker := health.NewChecker(cfg.Health.CheckTimeout)
+	repos, txManager, closeStorage, err := newStorage(ctx, cfg, checker)
 	if err != nil {
-		log.Fatal("Database init failed:", err)
+		fatal("storage init failed", err)
 	}
 
-	userRepo := repository.NewUserRepository(db)
-	teamRepo := repository.NewTeamRepository(db, userRepo)
-	prRepo := repository.NewPRRepository(db)
+	m := metrics.New()
+	tracer := tracing.NewTracer(newSpanExporter(cfg.Tracing))
+	observer := instrumented.Observers(m, tracer)
+	repos = instrumented.NewRepositories(repos, observer)
+	txManager = instrumented.NewTxManager(txManager, observer)
 
-	svc := service.NewService(userRepo, teamRepo, prRepo)
+	go purgeExpiredIdempotencyKeys(ctx, repos.Idempotency, cfg.Idempotency.TTL)
+
+	svc := service.NewService(repos, txManager, service.NewLeastLoadedSelector(), service.NewTeamPolicy(), m)
 	h := handlers.NewHandlers(svc)
 
+	if cfg.Auth.BootstrapToken != "" {
+		bootstrap := domain.APIToken{Name: "bootstrap", Role: domain.RoleAdmin}
+		if err := svc.EnsureToken(context.Background(), bootstrap, cfg.Auth.BootstrapToken); err != nil {
+			fatal("failed to register bootstrap token", err)
+		}
+	} else if cfg.Storage == config.StorageMemory {
+		slog.Warn("AUTH_BOOTSTRAP_TOKEN is not set, in-memory storage has no tokens and every request will be rejected")
+	}
+
 	e := echo.New()
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
+	e.HideBanner = true
+	e.HidePort = true
+	e.Use(handlers.Instrument(m))
+	e.Use(handlers.Trace(tracer))
+	e.Use(handlers.RequestID(logger))
+	e.Use(handlers.AccessLog())
+	e.Use(handlers.HandleErrors())
+	e.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{LogErrorFunc: handlers.RecoverLogger}))
+	e.Use(handlers.Authenticate(svc, "/livez", "/health", "/readyz", "/metrics"))
+	e.Use(handlers.Idempotency(repos.Idempotency, cfg.Idempotency.TTL))
+
+	admin := handlers.RequireRole(domain.RoleAdmin)
 
-	e.POST("/team/add", h.CreateTeam)
+	e.POST("/team/add", h.CreateTeam, admin)
 	e.GET("/team/get", h.GetTeam)
-	e.POST("/users/setIsActive", h.SetUserActive)
+	e.DELETE("/team", h.DeleteTeam, admin)
+	e.POST("/team/setFallbackTeams", h.SetFallbackTeams, admin)
+	e.POST("/team/deactivateUsers", h.DeactivateTeamUsers, admin)
+	e.POST("/team/addMembers", h.AddTeamMembers, admin)
+	e.POST("/team/removeMember", h.RemoveTeamMember, admin)
+	e.POST("/users/setIsActive", h.SetUserActive, admin)
+	e.POST("/users/moveTeam", h.MoveUserToTeam, admin)
+	e.POST("/users/setRole", h.SetUserRole, admin)
 	e.GET("/users/getReview", h.GetUserReviewPRs)
 	e.POST("/pullRequest/create", h.CreatePR)
+	e.GET("/pullRequest/get", h.GetPR)
+	e.GET("/pullRequest/list", h.ListPRs)
+	e.GET("/pullRequest/history", h.GetAssignmentHistory)
 	e.POST("/pullRequest/merge", h.MergePR)
+	e.POST("/pullRequest/close", h.ClosePR)
+	e.POST("/pullRequest/reopen", h.ReopenPR)
+	e.POST("/pullRequest/ready", h.MarkPRReady)
 	e.POST("/pullRequest/reassign", h.ReassignReviewer)
-	e.GET("/health", h.HealthCheck)
+	e.POST("/pullRequest/review", h.SubmitReview)
+	e.POST("/pullRequest/topUpReviewers", h.TopUpReviewers)
+	e.GET("/stats", h.GetStats)
+	e.GET("/livez", handlers.Livez)
+	// Deprecated alias kept for clients of the former /health endpoint.
+	e.GET("/health", handlers.Livez)
+	e.GET("/readyz", handlers.Readyz(checker))
+	e.GET("/metrics", echo.WrapHandler(m.Handler()))
 
 	srv := &http.Server{
 		Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
@@ -54,32 +145,149 @@ func main() {
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
-	log.Printf("Server is running on %s", srv.Addr)
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("server is running", slog.String("addr", srv.Addr))
+		serverErr <- e.StartServer(srv)
+	}()
+
+	select {
+	case err := <-serverErr:
+		fatal("server stopped", err)
+	case <-ctx.Done():
+	}
+	// A second signal kills the process without waiting for the drain.
+	stop()
+
+	slog.Info("shutting down", slog.Duration("delay", cfg.Server.ShutdownDelay),
+		slog.Duration("timeout", cfg.Server.ShutdownTimeout))
+	checker.Drain()
+	time.Sleep(cfg.Server.ShutdownDelay)
 
-	if err := e.StartServer(srv); err != nil {
-		log.Fatal("Server not running:", err)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to finish in-flight requests", slog.Any("error", err))
+	}
+	if err := tracer.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to flush spans", slog.Any("error", err))
 	}
+	if err := closeStorage(); err != nil {
+		slog.Error("failed to close storage", slog.Any("error", err))
+	}
+	slog.Info("server stopped")
 }
 
-func connectDatabase(dbConfig config.DatabaseConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		dbConfig.Host, dbConfig.Port, dbConfig.User, dbConfig.Password, dbConfig.Name, dbConfig.SSLMode)
+// newStorage also returns a function that releases the storage on shutdown.
+func newStorage(
+	ctx context.Context, cfg *config.Config, checker *health.Checker,
+) (domain.Repositories, domain.TxManager, func() error, error) {
+	if cfg.Storage == config.StorageMemory {
+		slog.Warn("using in-memory storage, data is lost on restart")
+		store := memory.NewStore()
+		return memory.NewRepositories(store), memory.NewTxManager(store), func() error { return nil }, nil
+	}
 
-	var db *gorm.DB
-	var err error
+	db, err := connectDatabase(ctx, cfg.Database)
+	if err != nil {
+		return domain.Repositories{}, nil, nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return domain.Repositories{}, nil, nil, err
+	}
 
-	for i := 0; i < 15; i++ {
-		db, err = database.NewPostgresDB(dsn)
-		if err == nil {
-			break
+	if cfg.Database.MigrateOnStart {
+		if err := runMigrate(db, []string{"up"}); err != nil {
+			return domain.Repositories{}, nil, nil, fmt.Errorf("migration failed: %w", err)
 		}
-		log.Printf("Attempt %d: Failed to connect to database: %v", i+1, err)
-		time.Sleep(2 * time.Second)
 	}
 
+	if err := addDatabaseChecks(checker, db, cfg.Health); err != nil {
+		return domain.Repositories{}, nil, nil, err
+	}
+
+	return repository.NewRepositories(db), repository.NewTxManager(db), sqlDB.Close, nil
+}
+
+func addDatabaseChecks(checker *health.Checker, db *gorm.DB, cfg config.HealthConfig) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	checker.Add("database", database.PingCheck(sqlDB))
+	checker.Add("migrations", database.MigrationsCheck(migrator))
+	checker.Add("connection_pool", database.PoolCheck(sqlDB, cfg.MaxPoolSaturation))
+	return nil
+}
+
+func newSpanExporter(cfg config.TracingConfig) tracing.Exporter {
+	switch cfg.Exporter {
+	case config.TracingExporterStdout:
+		return tracing.NewStdoutExporter(os.Stdout)
+	case config.TracingExporterOTLP:
+		slog.Info("exporting traces", slog.String("endpoint", cfg.OTLPEndpoint))
+		return tracing.NewOTLPExporter(cfg.OTLPEndpoint, cfg.ServiceName)
+	default:
+		return tracing.NopExporter{}
+	}
+}
+
+func connectDatabase(ctx context.Context, dbConfig config.DatabaseConfig) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		dbConfig.Host, dbConfig.Port, dbConfig.User, dbConfig.Password, dbConfig.Name, dbConfig.SSLMode)
+	if dbConfig.StatementTimeout > 0 {
+		dsn += fmt.Sprintf(" statement_timeout=%d", dbConfig.StatementTimeout.Milliseconds())
+	}
+
+	pool := database.PoolOptions{
+		MaxOpenConns:    dbConfig.MaxOpenConns,
+		MaxIdleConns:    dbConfig.MaxIdleConns,
+		ConnMaxLifetime: dbConfig.ConnMaxLifetime,
+	}
+	backoff := database.Backoff{
+		Initial:     dbConfig.ConnectInitialBackoff,
+		Max:         dbConfig.ConnectMaxBackoff,
+		MaxAttempts: dbConfig.ConnectMaxAttempts,
+	}
+
+	db, err := database.ConnectWithRetry(ctx, backoff, func() (*gorm.DB, error) {
+		return database.NewPostgresDB(dsn, pool, dbConfig.SlowQueryThreshold)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	return db, nil
 }
+
+func purgeExpiredIdempotencyKeys(ctx context.Context, store domain.IdempotencyRepository, ttl time.Duration) {
+	ticker := time.NewTicker(ttl)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := store.DeleteExpired(ctx, time.Now()); err != nil {
+			slog.Error("failed to purge expired idempotency keys", slog.Any("error", err))
+		}
+	}
+}
+
+// fatal logs msg with err, when there is one, and exits.
+func fatal(msg string, err error) {
+	if err != nil {
+		slog.Error(msg, slog.Any("error", err))
+	} else {
+		slog.Error(msg)
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+// runToken handles `token create -name NAME -role admin|user [-user USER_ID] | list | revoke ID`.
+func runToken(auth domain.AuthService, args []string) error {
+	ctx := context.Background()
+	if len(args) == 0 {
+		return fmt.Errorf("missing token command, expected create, list or revoke")
+	}
+
+	switch args[0] {
+	case "create":
+		flags := flag.NewFlagSet("token create", flag.ContinueOnError)
+		name := flags.String("name", "", "token name")
+		role := flags.String("role", domain.RoleUser, "token role: admin or user")
+		userID := flags.String("user", "", "user the token acts as, required for user tokens")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		secret, token, err := auth.CreateToken(ctx, domain.APIToken{Name: *name, Role: *role, UserID: *userID})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created token %d (%s, %s). Store it now, it is not shown again:\n%s\n",
+			token.ID, token.Name, token.Role, secret)
+	case "list":
+		tokens, err := auth.ListTokens(ctx)
+		if err != nil {
+			return err
+		}
+		for _, token := range tokens {
+			state := "active"
+			if token.RevokedAt != nil {
+				state = "revoked " + token.RevokedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%-6d %-30s %-6s %-20s %s\n", token.ID, token.Name, token.Role, token.UserID, state)
+		}
+	case "revoke":
+		if len(args) < 2 {
+			return fmt.Errorf("missing token ID")
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid token ID %q", args[1])
+		}
+		if err := auth.RevokeToken(ctx, id); err != nil {
+			return err
+		}
+		fmt.Printf("Revoked token %d\n", id)
+	default:
+		return fmt.Errorf("unknown token command %q, expected create, list or revoke", args[0])
+	}
+
+	return nil
+}
@@ -3,8 +3,11 @@ package e2e
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,6 +23,7 @@ func TestE2ESuite(t *testing.T) {
 }
 
 func (s *E2ETestSuite) SetupSuite() {
+	http.DefaultClient.Transport = authTransport{token: getAPIToken(), base: http.DefaultTransport}
 	waitForService(s.T())
 }
 
@@ -294,3 +298,886 @@ func (s *E2ETestSuite) Test06_ReassignWithLimitedAvailableReviewers() {
 	require.NoError(t, err)
 	assert.Equal(t, "NO_CANDIDATE", errorResp.Error.Code)
 }
+
+func (s *E2ETestSuite) Test07_ReviewLoadIsBalanced() {
+	t := s.T()
+
+	teamName := generateUniqueID("team-balance")
+	author := generateUniqueID("user-author")
+	reviewer1 := generateUniqueID("user-r1")
+	reviewer2 := generateUniqueID("user-r2")
+	reviewer3 := generateUniqueID("user-r3")
+
+	teamReq := TeamRequest{
+		TeamName: teamName,
+		Members: []UserRequest{
+			{UserID: author, Username: "Victor", IsActive: true},
+			{UserID: reviewer1, Username: "Wendy", IsActive: true},
+			{UserID: reviewer2, Username: "Xavier", IsActive: true},
+			{UserID: reviewer3, Username: "Yara", IsActive: true},
+		},
+	}
+	createTeam(t, teamReq)
+
+	assignments := make(map[string]int)
+	for i := 0; i < 3; i++ {
+		prResponse := createPR(t, CreatePRRequest{
+			PullRequestID:   generateUniqueID("pr-balance"),
+			PullRequestName: "Balanced PR",
+			AuthorID:        author,
+		})
+		require.Len(t, prResponse.PR.AssignedReviewers, 2)
+		for _, reviewer := range prResponse.PR.AssignedReviewers {
+			assignments[reviewer]++
+		}
+	}
+
+	assert.Equal(t, map[string]int{reviewer1: 2, reviewer2: 2, reviewer3: 2}, assignments)
+}
+
+func (s *E2ETestSuite) Test08_RequiredReviewersPerTeam() {
+	t := s.T()
+
+	platformTeam := generateUniqueID("team-platform")
+	platformMembers := []UserRequest{
+		{UserID: generateUniqueID("user-p1"), Username: "Zack", IsActive: true},
+		{UserID: generateUniqueID("user-p2"), Username: "Amy", IsActive: true},
+		{UserID: generateUniqueID("user-p3"), Username: "Ben", IsActive: true},
+		{UserID: generateUniqueID("user-p4"), Username: "Cleo", IsActive: true},
+	}
+	createTeam(t, TeamRequest{TeamName: platformTeam, RequiredReviewers: 3, Members: platformMembers})
+
+	docsTeam := generateUniqueID("team-docs")
+	docsMembers := []UserRequest{
+		{UserID: generateUniqueID("user-d1"), Username: "Dan", IsActive: true},
+		{UserID: generateUniqueID("user-d2"), Username: "Ella", IsActive: true},
+		{UserID: generateUniqueID("user-d3"), Username: "Finn", IsActive: true},
+	}
+	createTeam(t, TeamRequest{TeamName: docsTeam, RequiredReviewers: 1, Members: docsMembers})
+
+	assert.Equal(t, 3, getTeam(t, platformTeam).Team.RequiredReviewers)
+	assert.Equal(t, 1, getTeam(t, docsTeam).Team.RequiredReviewers)
+
+	platformPR := createPR(t, CreatePRRequest{
+		PullRequestID:   generateUniqueID("pr-platform"),
+		PullRequestName: "Platform PR",
+		AuthorID:        platformMembers[0].UserID,
+	})
+	assert.Len(t, platformPR.PR.AssignedReviewers, 3)
+
+	docsPR := createPR(t, CreatePRRequest{
+		PullRequestID:   generateUniqueID("pr-docs"),
+		PullRequestName: "Docs PR",
+		AuthorID:        docsMembers[0].UserID,
+	})
+	assert.Len(t, docsPR.PR.AssignedReviewers, 1)
+}
+
+func (s *E2ETestSuite) Test09_TopUpReviewers() {
+	t := s.T()
+
+	teamName := generateUniqueID("team-topup")
+	author := generateUniqueID("user-author")
+	activeMember := generateUniqueID("user-active")
+	joiningMember := generateUniqueID("user-joining")
+
+	createTeam(t, TeamRequest{
+		TeamName: teamName,
+		Members: []UserRequest{
+			{UserID: author, Username: "Gina", IsActive: true},
+			{UserID: activeMember, Username: "Hugo", IsActive: true},
+			{UserID: joiningMember, Username: "Iris", IsActive: false},
+		},
+	})
+
+	prID := generateUniqueID("pr-topup")
+	prResponse := createPR(t, CreatePRRequest{
+		PullRequestID:   prID,
+		PullRequestName: "Top Up PR",
+		AuthorID:        author,
+	})
+	require.Equal(t, []string{activeMember}, prResponse.PR.AssignedReviewers)
+
+	setUserActive(t, joiningMember, true)
+
+	toppedUp := topUpReviewers(t, prID)
+	assert.ElementsMatch(t, []string{activeMember, joiningMember}, toppedUp.PR.AssignedReviewers)
+}
+
+func (s *E2ETestSuite) Test10_FallbackTeamReviewers() {
+	t := s.T()
+
+	fallbackTeam := generateUniqueID("team-fallback")
+	fallbackMembers := []string{
+		generateUniqueID("user-f1"),
+		generateUniqueID("user-f2"),
+		generateUniqueID("user-f3"),
+	}
+	createTeam(t, TeamRequest{
+		TeamName: fallbackTeam,
+		Members: []UserRequest{
+			{UserID: fallbackMembers[0], Username: "Jack", IsActive: true},
+			{UserID: fallbackMembers[1], Username: "Kim", IsActive: true},
+			{UserID: fallbackMembers[2], Username: "Liam", IsActive: true},
+		},
+	})
+
+	teamName := generateUniqueID("team-main")
+	author := generateUniqueID("user-author")
+	teammate := generateUniqueID("user-teammate")
+	createTeam(t, TeamRequest{
+		TeamName:      teamName,
+		FallbackTeams: []string{fallbackTeam},
+		Members: []UserRequest{
+			{UserID: author, Username: "Maya", IsActive: true},
+			{UserID: teammate, Username: "Nick", IsActive: true},
+		},
+	})
+	assert.Equal(t, []string{fallbackTeam}, getTeam(t, teamName).Team.FallbackTeams)
+
+	prID := generateUniqueID("pr-fallback")
+	prResponse := createPR(t, CreatePRRequest{
+		PullRequestID:   prID,
+		PullRequestName: "Fallback PR",
+		AuthorID:        author,
+	})
+	require.Len(t, prResponse.PR.AssignedReviewers, 2)
+	assert.Contains(t, prResponse.PR.AssignedReviewers, teammate)
+	require.Len(t, prResponse.PR.FallbackReviewers, 1)
+	assert.Contains(t, fallbackMembers, prResponse.PR.FallbackReviewers[0])
+
+	reassignResponse := reassignReviewer(t, ReassignReviewerRequest{
+		PullRequestID: prID,
+		OldUserID:     teammate,
+	})
+	assert.Contains(t, fallbackMembers, reassignResponse.ReplacedBy)
+	assert.ElementsMatch(t, reassignResponse.PR.AssignedReviewers, reassignResponse.PR.FallbackReviewers)
+}
+
+func (s *E2ETestSuite) Test11_PRLifecycle() {
+	t := s.T()
+
+	teamName := generateUniqueID("team-lifecycle")
+	author := generateUniqueID("user-author")
+	createTeam(t, TeamRequest{
+		TeamName: teamName,
+		Members: []UserRequest{
+			{UserID: author, Username: "Olga", IsActive: true},
+			{UserID: generateUniqueID("user-l1"), Username: "Pete", IsActive: true},
+			{UserID: generateUniqueID("user-l2"), Username: "Rita", IsActive: true},
+		},
+	})
+
+	prID := generateUniqueID("pr-draft")
+	draft := createPR(t, CreatePRRequest{
+		PullRequestID:   prID,
+		PullRequestName: "Draft PR",
+		AuthorID:        author,
+		Draft:           true,
+	})
+	assert.Equal(t, "DRAFT", draft.PR.Status)
+	assert.Empty(t, draft.PR.AssignedReviewers)
+
+	changePRStatus(t, "merge", prID, http.StatusConflict)
+
+	ready := changePRStatus(t, "ready", prID, http.StatusOK)
+	assert.Equal(t, "OPEN", ready.PR.Status)
+	assert.Len(t, ready.PR.AssignedReviewers, 2)
+
+	closed := changePRStatus(t, "close", prID, http.StatusOK)
+	assert.Equal(t, "CLOSED", closed.PR.Status)
+	assert.Empty(t, closed.PR.AssignedReviewers)
+
+	changePRStatus(t, "ready", prID, http.StatusConflict)
+
+	reopened := changePRStatus(t, "reopen", prID, http.StatusOK)
+	assert.Equal(t, "OPEN", reopened.PR.Status)
+	assert.Len(t, reopened.PR.AssignedReviewers, 2)
+
+	mergePR(t, prID)
+	changePRStatus(t, "close", prID, http.StatusConflict)
+	changePRStatus(t, "reopen", prID, http.StatusConflict)
+}
+
+func (s *E2ETestSuite) Test12_GetAndListPRs() {
+	t := s.T()
+
+	teamName := generateUniqueID("team-list")
+	author := generateUniqueID("user-author")
+	reviewer := generateUniqueID("user-reviewer")
+	createTeam(t, TeamRequest{
+		TeamName: teamName,
+		Members: []UserRequest{
+			{UserID: author, Username: "Sara", IsActive: true},
+			{UserID: reviewer, Username: "Tom", IsActive: true},
+		},
+	})
+
+	prIDs := make([]string, 3)
+	for i := range prIDs {
+		prIDs[i] = generateUniqueID("pr-list")
+		createPR(t, CreatePRRequest{
+			PullRequestID:   prIDs[i],
+			PullRequestName: "Listed PR",
+			AuthorID:        author,
+		})
+	}
+	mergePR(t, prIDs[0])
+
+	fetched := getPR(t, prIDs[0])
+	assert.Equal(t, prIDs[0], fetched.PR.PullRequestID)
+	assert.Equal(t, "MERGED", fetched.PR.Status)
+
+	firstPage := listPRs(t, url.Values{"team_name": {teamName}, "limit": {"2"}})
+	require.Len(t, firstPage.PullRequests, 2)
+	require.NotEmpty(t, firstPage.NextCursor)
+
+	secondPage := listPRs(t, url.Values{"team_name": {teamName}, "limit": {"2"}, "cursor": {firstPage.NextCursor}})
+	require.Len(t, secondPage.PullRequests, 1)
+	assert.Empty(t, secondPage.NextCursor)
+
+	var listed []string
+	for _, pr := range append(firstPage.PullRequests, secondPage.PullRequests...) {
+		listed = append(listed, pr.PullRequestID)
+	}
+	assert.ElementsMatch(t, prIDs, listed)
+
+	merged := listPRs(t, url.Values{"author_id": {author}, "status": {"MERGED"}})
+	require.Len(t, merged.PullRequests, 1)
+	assert.Equal(t, prIDs[0], merged.PullRequests[0].PullRequestID)
+
+	reviewing := listPRs(t, url.Values{"reviewer_id": {reviewer}, "status": {"OPEN"}})
+	assert.Len(t, reviewing.PullRequests, 2)
+}
+
+func (s *E2ETestSuite) Test13_ReviewsGateMerge() {
+	t := s.T()
+
+	teamName := generateUniqueID("team-reviews")
+	author := generateUniqueID("user-author")
+	createTeam(t, TeamRequest{
+		TeamName:          teamName,
+		RequiredApprovals: 1,
+		Members: []UserRequest{
+			{UserID: author, Username: "Uma", IsActive: true},
+			{UserID: generateUniqueID("user-rv1"), Username: "Vera", IsActive: true},
+			{UserID: generateUniqueID("user-rv2"), Username: "Walt", IsActive: true},
+		},
+	})
+
+	prID := generateUniqueID("pr-reviews")
+	prResponse := createPR(t, CreatePRRequest{
+		PullRequestID:   prID,
+		PullRequestName: "Reviewed PR",
+		AuthorID:        author,
+	})
+	require.Len(t, prResponse.PR.AssignedReviewers, 2)
+	reviewer := prResponse.PR.AssignedReviewers[0]
+
+	changePRStatus(t, "merge", prID, http.StatusConflict)
+
+	submitReview(t, prID, author, "APPROVED", http.StatusConflict)
+
+	changesRequested := submitReview(t, prID, reviewer, "CHANGES_REQUESTED", http.StatusOK)
+	states := make(map[string]string)
+	for _, review := range changesRequested.PR.Reviews {
+		states[review.ReviewerID] = review.State
+	}
+	assert.Equal(t, "CHANGES_REQUESTED", states[reviewer])
+	assert.Equal(t, "PENDING", states[prResponse.PR.AssignedReviewers[1]])
+
+	changePRStatus(t, "merge", prID, http.StatusConflict)
+
+	submitReview(t, prID, reviewer, "APPROVED", http.StatusOK)
+	mergePR(t, prID)
+	assert.Equal(t, "MERGED", getPR(t, prID).PR.Status)
+}
+
+func (s *E2ETestSuite) Test14_AssignmentHistory() {
+	t := s.T()
+
+	teamName := generateUniqueID("team-history")
+	author := generateUniqueID("user-author")
+	createTeam(t, TeamRequest{
+		TeamName: teamName,
+		Members: []UserRequest{
+			{UserID: author, Username: "Xena", IsActive: true},
+			{UserID: generateUniqueID("user-h1"), Username: "Yuri", IsActive: true},
+			{UserID: generateUniqueID("user-h2"), Username: "Zoe", IsActive: true},
+			{UserID: generateUniqueID("user-h3"), Username: "Adam", IsActive: true},
+		},
+	})
+
+	prID := generateUniqueID("pr-history")
+	prResponse := createPR(t, CreatePRRequest{
+		PullRequestID:   prID,
+		PullRequestName: "History PR",
+		AuthorID:        author,
+	})
+	require.Len(t, prResponse.PR.AssignedReviewers, 2)
+	oldReviewer := prResponse.PR.AssignedReviewers[0]
+
+	reassignResponse := reassignReviewer(t, ReassignReviewerRequest{
+		PullRequestID: prID,
+		OldUserID:     oldReviewer,
+		Reason:        "on vacation",
+	})
+
+	history := getHistory(t, prID)
+	require.Len(t, history.History, 4)
+
+	for _, entry := range history.History[:2] {
+		assert.Equal(t, "ASSIGNED", entry.Action)
+		assert.Equal(t, "CREATE", entry.Operation)
+	}
+
+	unassigned := history.History[2]
+	assert.Equal(t, oldReviewer, unassigned.UserID)
+	assert.Equal(t, "UNASSIGNED", unassigned.Action)
+	assert.Equal(t, "REASSIGN", unassigned.Operation)
+	assert.Equal(t, "on vacation", unassigned.Reason)
+
+	assigned := history.History[3]
+	assert.Equal(t, reassignResponse.ReplacedBy, assigned.UserID)
+	assert.Equal(t, "ASSIGNED", assigned.Action)
+	assert.Equal(t, "REASSIGN", assigned.Operation)
+}
+
+func (s *E2ETestSuite) Test15_DeactivationReassignsOpenReviews() {
+	t := s.T()
+
+	teamName := generateUniqueID("team-deactivate")
+	author := generateUniqueID("user-author")
+	createTeam(t, TeamRequest{
+		TeamName: teamName,
+		Members: []UserRequest{
+			{UserID: author, Username: "Bella", IsActive: true},
+			{UserID: generateUniqueID("user-da1"), Username: "Carl", IsActive: true},
+			{UserID: generateUniqueID("user-da2"), Username: "Dina", IsActive: true},
+			{UserID: generateUniqueID("user-da3"), Username: "Egor", IsActive: true},
+		},
+	})
+
+	prID := generateUniqueID("pr-deactivate")
+	prResponse := createPR(t, CreatePRRequest{
+		PullRequestID:   prID,
+		PullRequestName: "Deactivation PR",
+		AuthorID:        author,
+	})
+	require.Len(t, prResponse.PR.AssignedReviewers, 2)
+	leaving := prResponse.PR.AssignedReviewers[0]
+
+	report := setUserActive(t, leaving, false)
+	require.Len(t, report.Reassigned, 1)
+	assert.Empty(t, report.NoCandidate)
+	assert.Equal(t, prID, report.Reassigned[0].PullRequestID)
+	assert.Equal(t, leaving, report.Reassigned[0].OldReviewerID)
+
+	updated := getPR(t, prID)
+	assert.Len(t, updated.PR.AssignedReviewers, 2)
+	assert.NotContains(t, updated.PR.AssignedReviewers, leaving)
+	assert.Contains(t, updated.PR.AssignedReviewers, report.Reassigned[0].NewReviewerID)
+}
+
+func (s *E2ETestSuite) Test16_DeactivationWithoutCandidate() {
+	t := s.T()
+
+	teamName := generateUniqueID("team-nocandidate")
+	author := generateUniqueID("user-author")
+	reviewer := generateUniqueID("user-only")
+	createTeam(t, TeamRequest{
+		TeamName: teamName,
+		Members: []UserRequest{
+			{UserID: author, Username: "Fiona", IsActive: true},
+			{UserID: reviewer, Username: "Gleb", IsActive: true},
+		},
+	})
+
+	prID := generateUniqueID("pr-nocandidate")
+	createPR(t, CreatePRRequest{
+		PullRequestID:   prID,
+		PullRequestName: "Lonely PR",
+		AuthorID:        author,
+	})
+
+	report := setUserActive(t, reviewer, false)
+	assert.Empty(t, report.Reassigned)
+	require.Len(t, report.NoCandidate, 1)
+	assert.Equal(t, prID, report.NoCandidate[0].PullRequestID)
+
+	assert.Empty(t, getPR(t, prID).PR.AssignedReviewers)
+}
+
+func (s *E2ETestSuite) Test17_BulkTeamDeactivation() {
+	t := s.T()
+
+	teamName := generateUniqueID("team-bulk")
+	author := generateUniqueID("user-author")
+	members := []UserRequest{{UserID: author, Username: "Hanna", IsActive: true}}
+	for i := 0; i < 6; i++ {
+		members = append(members, UserRequest{UserID: generateUniqueID("user-bulk"), Username: "Member", IsActive: true})
+	}
+	createTeam(t, TeamRequest{TeamName: teamName, Members: members})
+
+	prIDs := make([]string, 5)
+	for i := range prIDs {
+		prIDs[i] = generateUniqueID("pr-bulk")
+		createPR(t, CreatePRRequest{
+			PullRequestID:   prIDs[i],
+			PullRequestName: "Bulk PR",
+			AuthorID:        author,
+		})
+	}
+
+	leaving := []string{members[1].UserID, members[2].UserID, members[3].UserID}
+	report := deactivateTeamUsers(t, teamName, leaving)
+	assert.ElementsMatch(t, leaving, report.DeactivatedUsers)
+	assert.Empty(t, report.NoCandidate)
+
+	for _, prID := range prIDs {
+		reviewers := getPR(t, prID).PR.AssignedReviewers
+		assert.Len(t, reviewers, 2)
+		for _, userID := range leaving {
+			assert.NotContains(t, reviewers, userID)
+		}
+	}
+
+	team := getTeam(t, teamName)
+	for _, member := range team.Team.Members {
+		assert.Equal(t, !contains(leaving, member.UserID), member.IsActive, member.UserID)
+	}
+}
+
+func (s *E2ETestSuite) Test18_Stats() {
+	t := s.T()
+
+	teamName := generateUniqueID("team-stats")
+	author := generateUniqueID("user-author")
+	reviewer1 := generateUniqueID("user-reviewer")
+	reviewer2 := generateUniqueID("user-reviewer")
+	createTeam(t, TeamRequest{
+		TeamName: teamName,
+		Members: []UserRequest{
+			{UserID: author, Username: "Ivan", IsActive: true},
+			{UserID: reviewer1, Username: "Julia", IsActive: true},
+			{UserID: reviewer2, Username: "Kirill", IsActive: true},
+		},
+	})
+
+	openPR := generateUniqueID("pr-stats-open")
+	mergedPR := generateUniqueID("pr-stats-merged")
+	createPR(t, CreatePRRequest{PullRequestID: openPR, PullRequestName: "Open PR", AuthorID: author})
+	createPR(t, CreatePRRequest{PullRequestID: mergedPR, PullRequestName: "Merged PR", AuthorID: author})
+	mergePR(t, mergedPR)
+
+	stats := getStats(t, url.Values{"team_name": {teamName}})
+
+	require.Len(t, stats.Users, 3)
+	for _, user := range stats.Users {
+		assert.Equal(t, teamName, user.TeamName)
+		if user.UserID == author {
+			assert.Zero(t, user.TotalAssignments)
+			continue
+		}
+		assert.Equal(t, 1, user.CurrentAssignments, user.UserID)
+		assert.Equal(t, 2, user.TotalAssignments, user.UserID)
+	}
+
+	require.Len(t, stats.Teams, 1)
+	assert.Equal(t, 1, stats.Teams[0].OpenPRs)
+
+	require.Len(t, stats.PullRequests, 2)
+	for _, pr := range stats.PullRequests {
+		assert.Equal(t, 2, pr.Reviewers, pr.PullRequestID)
+	}
+	require.NotNil(t, stats.AverageTimeToMergeSeconds)
+	assert.GreaterOrEqual(t, *stats.AverageTimeToMergeSeconds, 0.0)
+
+	stats = getStats(t, url.Values{"team_name": {teamName}, "limit": {"1"}})
+	require.Len(t, stats.PullRequests, 1)
+	assert.Equal(t, mergedPR, stats.PullRequests[0].PullRequestID)
+
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	stats = getStats(t, url.Values{"team_name": {teamName}, "from": {future}})
+	assert.Empty(t, stats.PullRequests)
+	assert.Nil(t, stats.AverageTimeToMergeSeconds)
+}
+
+func (s *E2ETestSuite) Test19_CreateTeamIsAtomic() {
+	t := s.T()
+	baseURL := getBaseURL()
+
+	existingUser := generateUniqueID("user-existing")
+	createTeam(t, TeamRequest{
+		TeamName: generateUniqueID("team-first"),
+		Members:  []UserRequest{{UserID: existingUser, Username: "Lena", IsActive: true}},
+	})
+
+	teamName := generateUniqueID("team-atomic")
+	newUser := generateUniqueID("user-new")
+	body, err := json.Marshal(TeamRequest{
+		TeamName: teamName,
+		Members: []UserRequest{
+			{UserID: newUser, Username: "Maxim", IsActive: true},
+			{UserID: existingUser, Username: "Lena", IsActive: true},
+		},
+	})
+	require.NoError(t, err)
+
+	resp, err := http.Post(baseURL+"/team/add", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	var errResp ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Equal(t, "USER_IN_OTHER_TEAM", errResp.Error.Code)
+
+	resp, err = http.Get(baseURL + "/team/get?team_name=" + teamName)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	createTeam(t, TeamRequest{
+		TeamName: teamName,
+		Members:  []UserRequest{{UserID: newUser, Username: "Maxim", IsActive: true}},
+	})
+	team := getTeam(t, teamName)
+	require.Len(t, team.Team.Members, 1)
+	assert.Equal(t, newUser, team.Team.Members[0].UserID)
+}
+
+func (s *E2ETestSuite) Test20_ConcurrentReassignDoesNotLoseUpdates() {
+	t := s.T()
+	baseURL := getBaseURL()
+
+	teamName := generateUniqueID("team-concurrent")
+	author := generateUniqueID("user-author")
+	members := []UserRequest{{UserID: author, Username: "Nadia", IsActive: true}}
+	for i := 0; i < 8; i++ {
+		members = append(members, UserRequest{UserID: generateUniqueID("user-concurrent"), Username: "Member", IsActive: true})
+	}
+	createTeam(t, TeamRequest{TeamName: teamName, Members: members})
+
+	prID := generateUniqueID("pr-concurrent")
+	pr := createPR(t, CreatePRRequest{PullRequestID: prID, PullRequestName: "Concurrent PR", AuthorID: author})
+	require.Len(t, pr.PR.AssignedReviewers, 2)
+
+	type result struct {
+		status     int
+		replacedBy string
+	}
+	results := make(chan result, len(pr.PR.AssignedReviewers))
+	for _, oldReviewer := range pr.PR.AssignedReviewers {
+		go func(oldReviewer string) {
+			body, _ := json.Marshal(ReassignReviewerRequest{PullRequestID: prID, OldUserID: oldReviewer})
+			resp, err := http.Post(baseURL+"/pullRequest/reassign", "application/json", bytes.NewBuffer(body))
+			if err != nil {
+				results <- result{}
+				return
+			}
+			defer resp.Body.Close()
+
+			var reassignResponse PRResponse
+			_ = json.NewDecoder(resp.Body).Decode(&reassignResponse)
+			results <- result{status: resp.StatusCode, replacedBy: reassignResponse.ReplacedBy}
+		}(oldReviewer)
+	}
+
+	var succeeded []result
+	for range pr.PR.AssignedReviewers {
+		res := <-results
+		assert.Contains(t, []int{http.StatusOK, http.StatusConflict}, res.status)
+		if res.status == http.StatusOK {
+			succeeded = append(succeeded, res)
+		}
+	}
+	require.NotEmpty(t, succeeded)
+
+	reviewers := getPR(t, prID).PR.AssignedReviewers
+	assert.Len(t, reviewers, 2)
+	assert.NotEqual(t, reviewers[0], reviewers[1])
+	for _, res := range succeeded {
+		assert.Contains(t, reviewers, res.replacedBy)
+	}
+}
+
+func (s *E2ETestSuite) Test21_IdempotencyKeyReplaysResponse() {
+	t := s.T()
+
+	teamName := generateUniqueID("team-idempotent")
+	author := generateUniqueID("user-author")
+	createTeam(t, TeamRequest{
+		TeamName: teamName,
+		Members: []UserRequest{
+			{UserID: author, Username: "Oleg", IsActive: true},
+			{UserID: generateUniqueID("user-reviewer"), Username: "Polina", IsActive: true},
+			{UserID: generateUniqueID("user-reviewer"), Username: "Roman", IsActive: true},
+		},
+	})
+
+	key := generateUniqueID("key")
+	prReq := CreatePRRequest{
+		PullRequestID:   generateUniqueID("pr-idempotent"),
+		PullRequestName: "Idempotent PR",
+		AuthorID:        author,
+	}
+
+	first, firstBody := postWithIdempotencyKey(t, "/pullRequest/create", key, prReq)
+	assert.Equal(t, http.StatusCreated, first.StatusCode)
+	assert.Empty(t, first.Header.Get("Idempotent-Replayed"))
+
+	retry, retryBody := postWithIdempotencyKey(t, "/pullRequest/create", key, prReq)
+	assert.Equal(t, http.StatusCreated, retry.StatusCode)
+	assert.Equal(t, "true", retry.Header.Get("Idempotent-Replayed"))
+	assert.JSONEq(t, string(firstBody), string(retryBody))
+
+	prReq.PullRequestName = "Different PR"
+	reused, _ := postWithIdempotencyKey(t, "/pullRequest/create", key, prReq)
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.StatusCode)
+}
+
+func (s *E2ETestSuite) Test22_ReviewerIDsWithQuotes() {
+	t := s.T()
+
+	teamName := generateUniqueID("team-quotes")
+	author := generateUniqueID("user-author")
+	reviewer1 := generateUniqueID(`user-"quoted"`)
+	reviewer2 := generateUniqueID(`user-o'brien`)
+	createTeam(t, TeamRequest{
+		TeamName: teamName,
+		Members: []UserRequest{
+			{UserID: author, Username: "Sergey", IsActive: true},
+			{UserID: reviewer1, Username: "Tanya", IsActive: true},
+			{UserID: reviewer2, Username: "Ulyana", IsActive: true},
+		},
+	})
+
+	prID := generateUniqueID("pr-quotes")
+	pr := createPR(t, CreatePRRequest{PullRequestID: prID, PullRequestName: "Quoted PR", AuthorID: author})
+	assert.ElementsMatch(t, []string{reviewer1, reviewer2}, pr.PR.AssignedReviewers)
+
+	for _, reviewer := range []string{reviewer1, reviewer2} {
+		reviews := getUserReviews(t, reviewer)
+		require.Len(t, reviews.PullRequests, 1, reviewer)
+		assert.Equal(t, prID, reviews.PullRequests[0].PullRequestID)
+	}
+
+	assert.Equal(t, pr.PR.AssignedReviewers, getPR(t, prID).PR.AssignedReviewers)
+}
+
+func (s *E2ETestSuite) Test23_TeamMembershipChanges() {
+	t := s.T()
+
+	teamName := generateUniqueID("team-members")
+	otherTeam := generateUniqueID("team-other")
+	author := generateUniqueID("user-author")
+	reviewer1 := generateUniqueID("user-reviewer")
+	reviewer2 := generateUniqueID("user-reviewer")
+	createTeam(t, TeamRequest{
+		TeamName: teamName,
+		Members: []UserRequest{
+			{UserID: author, Username: "Vadim", IsActive: true},
+			{UserID: reviewer1, Username: "Vera", IsActive: true},
+			{UserID: reviewer2, Username: "Viktor", IsActive: true},
+		},
+	})
+	createTeam(t, TeamRequest{TeamName: otherTeam})
+
+	newcomer := generateUniqueID("user-newcomer")
+	team := addTeamMembers(t, teamName, []UserRequest{{UserID: newcomer, Username: "Yana", IsActive: true}},
+		http.StatusOK)
+	assert.Len(t, team.Team.Members, 4)
+	addTeamMembers(t, otherTeam, []UserRequest{{UserID: newcomer, Username: "Yana", IsActive: true}},
+		http.StatusConflict)
+
+	prID := generateUniqueID("pr-members")
+	pr := createPR(t, CreatePRRequest{PullRequestID: prID, PullRequestName: "Members PR", AuthorID: author})
+	require.Len(t, pr.PR.AssignedReviewers, 2)
+
+	moving := pr.PR.AssignedReviewers[0]
+	moved := moveUser(t, moving, otherTeam, "Renamed")
+	assert.Equal(t, otherTeam, moved.User.TeamName)
+	assert.Equal(t, "Renamed", moved.User.Username)
+	require.Len(t, moved.Reassigned, 1)
+	assert.NotContains(t, getPR(t, prID).PR.AssignedReviewers, moving)
+
+	removing := getPR(t, prID).PR.AssignedReviewers[0]
+	report := removeTeamMember(t, teamName, removing, http.StatusOK)
+	assert.Empty(t, report.Reassigned)
+	require.Len(t, report.NoCandidate, 1)
+	assert.NotContains(t, getPR(t, prID).PR.AssignedReviewers, removing)
+
+	removeTeamMember(t, teamName, author, http.StatusBadRequest)
+
+	members := getTeam(t, teamName).Team.Members
+	assert.Len(t, members, 2)
+	assert.Len(t, getTeam(t, otherTeam).Team.Members, 1)
+}
+
+func (s *E2ETestSuite) Test24_TeamArchivalAndDeletion() {
+	t := s.T()
+	baseURL := getBaseURL()
+
+	teamName := generateUniqueID("team-archive")
+	author := generateUniqueID("user-author")
+	reviewer1 := generateUniqueID("user-reviewer")
+	reviewer2 := generateUniqueID("user-reviewer")
+	createTeam(t, TeamRequest{
+		TeamName: teamName,
+		Members: []UserRequest{
+			{UserID: author, Username: "Zakhar", IsActive: true},
+			{UserID: reviewer1, Username: "Zlata", IsActive: true},
+			{UserID: reviewer2, Username: "Zoya", IsActive: true},
+		},
+	})
+
+	prID := generateUniqueID("pr-archive")
+	createPR(t, CreatePRRequest{PullRequestID: prID, PullRequestName: "Archive PR", AuthorID: author})
+
+	report := deleteTeam(t, url.Values{"team_name": {teamName}, "hard": {"true"}}, http.StatusConflict)
+	assert.False(t, report.Archived)
+
+	report = deleteTeam(t, url.Values{"team_name": {teamName}, "close_open_prs": {"true"}}, http.StatusOK)
+	assert.True(t, report.Archived)
+	assert.Equal(t, []string{prID}, report.ClosedPullRequests)
+	assert.ElementsMatch(t, []string{author, reviewer1, reviewer2}, report.DeactivatedUsers)
+	assert.Equal(t, "CLOSED", getPR(t, prID).PR.Status)
+
+	resp, err := http.Get(baseURL + "/team/get?team_name=" + teamName)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Get(baseURL + "/team/get?include_archived=true&team_name=" + teamName)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	emptyTeam := generateUniqueID("team-empty")
+	createTeam(t, TeamRequest{
+		TeamName: emptyTeam,
+		Members:  []UserRequest{{UserID: generateUniqueID("user-idle"), Username: "Idle", IsActive: true}},
+	})
+	report = deleteTeam(t, url.Values{"team_name": {emptyTeam}, "hard": {"true"}}, http.StatusOK)
+	assert.False(t, report.Archived)
+
+	resp, err = http.Get(baseURL + "/team/get?include_archived=true&team_name=" + emptyTeam)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func (s *E2ETestSuite) Test25_Authentication() {
+	t := s.T()
+	baseURL := getBaseURL()
+	anonymous := &http.Client{}
+
+	resp, err := anonymous.Get(baseURL + "/team/get?team_name=any")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "Bearer", resp.Header.Get("WWW-Authenticate"))
+
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/team/get?team_name=any", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer not-a-real-token")
+	resp, err = anonymous.Do(req)
+	require.NoError(t, err)
+	var errResp ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "UNAUTHORIZED", errResp.Error.Code)
+
+	teamName := generateUniqueID("team-auth")
+	author := generateUniqueID("user-author")
+	createTeam(t, TeamRequest{
+		TeamName: teamName,
+		Members:  []UserRequest{{UserID: author, Username: "Arkady", IsActive: true}},
+	})
+
+	prID := generateUniqueID("pr-auth")
+	createPR(t, CreatePRRequest{PullRequestID: prID, PullRequestName: "Auth PR", AuthorID: author})
+	mergePR(t, prID)
+	assert.Equal(t, "token:bootstrap", getPR(t, prID).PR.MergedBy)
+}
+
+func (s *E2ETestSuite) Test26_Metrics() {
+	t := s.T()
+
+	teamName := generateUniqueID("team-metrics")
+	author := generateUniqueID("user-author")
+	reviewer := generateUniqueID("user-reviewer")
+	createTeam(t, TeamRequest{
+		TeamName: teamName,
+		Members: []UserRequest{
+			{UserID: author, Username: "Mark", IsActive: true},
+			{UserID: reviewer, Username: "Maya", IsActive: true},
+		},
+	})
+	createPR(t, CreatePRRequest{PullRequestID: generateUniqueID("pr-metrics"), PullRequestName: "Metrics PR", AuthorID: author})
+
+	resp, err := (&http.Client{}).Get(getBaseURL() + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/plain; version=0.0.4")
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	text := string(body)
+
+	assert.Contains(t, text, "# TYPE http_request_duration_seconds histogram")
+	assert.Contains(t, text, `http_requests_total{method="POST",route="/pullRequest/create",status="201"}`)
+	assert.Contains(t, text, `db_query_duration_seconds_count{repository="pull_requests",method="Create"}`)
+	assert.Contains(t, text, "\npull_requests_created_total ")
+	assert.Regexp(t, `\npull_requests_understaffed_total [1-9]`, text)
+	assert.Contains(t, text, "\nreviewer_no_candidate_errors_total ")
+}
+
+func (s *E2ETestSuite) Test27_RequestID() {
+	t := s.T()
+	baseURL := getBaseURL()
+
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/livez", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Request-ID", "e2e-request-42")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "e2e-request-42", resp.Header.Get("X-Request-ID"))
+
+	resp, err = http.Get(baseURL + "/livez")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Regexp(t, `^[0-9a-f]{32}$`, resp.Header.Get("X-Request-ID"))
+}
+
+func (s *E2ETestSuite) Test28_Probes() {
+	t := s.T()
+	baseURL := getBaseURL()
+
+	for _, path := range []string{"/livez", "/health"} {
+		resp, err := http.Get(baseURL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+	}
+
+	resp, err := http.Get(baseURL + "/readyz")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var report struct {
+		Status string `json:"status"`
+		Checks map[string]struct {
+			Status string `json:"status"`
+		} `json:"checks"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, "ok", report.Status)
+	for name, check := range report.Checks {
+		assert.Equal(t, "ok", check.Status, name)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"testing"
 	"time"
@@ -20,6 +22,28 @@ func getBaseURL() string {
 	return "http://localhost:8080"
 }
 
+func getAPIToken() string {
+	if token := os.Getenv("E2E_API_TOKEN"); token != "" {
+		return token
+	}
+	return "e2e-admin-token-0123456789"
+}
+
+// authTransport adds the admin bearer token to every request that has no
+// Authorization header, so the helpers can keep using http.Get and http.Post.
+type authTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (t authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	return t.base.RoundTrip(req)
+}
+
 type UserRequest struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
@@ -27,19 +51,24 @@ type UserRequest struct {
 }
 
 type TeamRequest struct {
-	TeamName string        `json:"team_name"`
-	Members  []UserRequest `json:"members"`
+	TeamName          string        `json:"team_name"`
+	RequiredReviewers int           `json:"required_reviewers,omitempty"`
+	RequiredApprovals int           `json:"required_approvals,omitempty"`
+	FallbackTeams     []string      `json:"fallback_teams,omitempty"`
+	Members           []UserRequest `json:"members"`
 }
 
 type CreatePRRequest struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
+	Draft           bool   `json:"draft,omitempty"`
 }
 
 type ReassignReviewerRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 	OldUserID     string `json:"old_user_id"`
+	Reason        string `json:"reason,omitempty"`
 }
 
 type PRResponse struct {
@@ -49,14 +78,92 @@ type PRResponse struct {
 		AuthorID          string   `json:"author_id"`
 		Status            string   `json:"status"`
 		AssignedReviewers []string `json:"assigned_reviewers"`
+		FallbackReviewers []string `json:"fallback_reviewers"`
+		MergedBy          string   `json:"mergedBy"`
+		Reviews           []struct {
+			ReviewerID string `json:"reviewer_id"`
+			State      string `json:"state"`
+		} `json:"reviews"`
 	} `json:"pr"`
 	ReplacedBy string `json:"replaced_by,omitempty"`
 }
 
+type PRListResponse struct {
+	PullRequests []struct {
+		PullRequestID string `json:"pull_request_id"`
+		AuthorID      string `json:"author_id"`
+		Status        string `json:"status"`
+	} `json:"pull_requests"`
+	NextCursor string `json:"next_cursor"`
+}
+
+type StatsResponse struct {
+	Users []struct {
+		UserID             string `json:"user_id"`
+		TeamName           string `json:"team_name"`
+		CurrentAssignments int    `json:"current_assignments"`
+		TotalAssignments   int    `json:"total_assignments"`
+	} `json:"users"`
+	Teams []struct {
+		TeamName string `json:"team_name"`
+		OpenPRs  int    `json:"open_prs"`
+	} `json:"teams"`
+	PullRequests []struct {
+		PullRequestID string `json:"pull_request_id"`
+		Status        string `json:"status"`
+		Reviewers     int    `json:"reviewers"`
+	} `json:"pull_requests"`
+	AverageTimeToMergeSeconds *float64 `json:"average_time_to_merge_seconds"`
+}
+
+type HistoryResponse struct {
+	PullRequestID string `json:"pull_request_id"`
+	History       []struct {
+		UserID    string `json:"user_id"`
+		Action    string `json:"action"`
+		Operation string `json:"operation"`
+		Reason    string `json:"reason"`
+	} `json:"history"`
+}
+
+type ReviewerReplacement struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id"`
+}
+
+type SetUserActiveResponse struct {
+	DeactivatedUsers []string              `json:"deactivated_users"`
+	Reassigned       []ReviewerReplacement `json:"reassigned"`
+	NoCandidate      []ReviewerReplacement `json:"no_candidate"`
+}
+
+type TeamDeletionResponse struct {
+	TeamName           string                `json:"team_name"`
+	Archived           bool                  `json:"archived"`
+	ClosedPullRequests []string              `json:"closed_pull_requests"`
+	DeactivatedUsers   []string              `json:"deactivated_users"`
+	Reassigned         []ReviewerReplacement `json:"reassigned"`
+	NoCandidate        []ReviewerReplacement `json:"no_candidate"`
+}
+
+type MoveUserResponse struct {
+	User struct {
+		UserID   string `json:"user_id"`
+		Username string `json:"username"`
+		TeamName string `json:"team_name"`
+		IsActive bool   `json:"is_active"`
+	} `json:"user"`
+	Reassigned  []ReviewerReplacement `json:"reassigned"`
+	NoCandidate []ReviewerReplacement `json:"no_candidate"`
+}
+
 type TeamResponse struct {
 	Team struct {
-		TeamName string `json:"team_name"`
-		Members  []struct {
+		TeamName          string   `json:"team_name"`
+		RequiredReviewers int      `json:"required_reviewers"`
+		FallbackTeams     []string `json:"fallback_teams"`
+		Members           []struct {
 			UserID   string `json:"user_id"`
 			Username string `json:"username"`
 			IsActive bool   `json:"is_active"`
@@ -74,7 +181,7 @@ type ErrorResponse struct {
 func waitForService(t *testing.T) {
 	baseURL := getBaseURL()
 	for i := 0; i < 30; i++ {
-		resp, err := http.Get(baseURL + "/health")
+		resp, err := http.Get(baseURL + "/readyz")
 		if err == nil && resp.StatusCode == http.StatusOK {
 			resp.Body.Close()
 			return
@@ -150,7 +257,7 @@ func reassignReviewer(t *testing.T, req ReassignReviewerRequest) *PRResponse {
 	return &prResponse
 }
 
-func setUserActive(t *testing.T, userID string, isActive bool) {
+func setUserActive(t *testing.T, userID string, isActive bool) *SetUserActiveResponse {
 	baseURL := getBaseURL()
 	req := map[string]interface{}{
 		"user_id":   userID,
@@ -165,6 +272,124 @@ func setUserActive(t *testing.T, userID string, isActive bool) {
 	defer resp.Body.Close()
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode, "Failed to set user active status")
+
+	var activeResponse SetUserActiveResponse
+	err = json.NewDecoder(resp.Body).Decode(&activeResponse)
+	require.NoError(t, err)
+
+	return &activeResponse
+}
+
+func deactivateTeamUsers(t *testing.T, teamName string, userIDs []string) *SetUserActiveResponse {
+	baseURL := getBaseURL()
+	req := map[string]interface{}{
+		"team_name": teamName,
+		"user_ids":  userIDs,
+	}
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	resp, err := http.Post(baseURL+"/team/deactivateUsers", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Failed to deactivate team users")
+
+	var report SetUserActiveResponse
+	err = json.NewDecoder(resp.Body).Decode(&report)
+	require.NoError(t, err)
+
+	return &report
+}
+
+func addTeamMembers(t *testing.T, teamName string, members []UserRequest, expectedStatus int) *TeamResponse {
+	baseURL := getBaseURL()
+	req := map[string]interface{}{
+		"team_name": teamName,
+		"members":   members,
+	}
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	resp, err := http.Post(baseURL+"/team/addMembers", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, expectedStatus, resp.StatusCode, "Unexpected status adding team members")
+
+	var teamResponse TeamResponse
+	err = json.NewDecoder(resp.Body).Decode(&teamResponse)
+	require.NoError(t, err)
+
+	return &teamResponse
+}
+
+func removeTeamMember(t *testing.T, teamName, userID string, expectedStatus int) *SetUserActiveResponse {
+	baseURL := getBaseURL()
+	req := map[string]interface{}{
+		"team_name": teamName,
+		"user_id":   userID,
+	}
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	resp, err := http.Post(baseURL+"/team/removeMember", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, expectedStatus, resp.StatusCode, "Unexpected status removing team member")
+
+	var report SetUserActiveResponse
+	err = json.NewDecoder(resp.Body).Decode(&report)
+	require.NoError(t, err)
+
+	return &report
+}
+
+func moveUser(t *testing.T, userID, teamName, username string) *MoveUserResponse {
+	baseURL := getBaseURL()
+	req := map[string]interface{}{
+		"user_id":   userID,
+		"team_name": teamName,
+		"username":  username,
+	}
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	resp, err := http.Post(baseURL+"/users/moveTeam", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Failed to move user")
+
+	var moveResponse MoveUserResponse
+	err = json.NewDecoder(resp.Body).Decode(&moveResponse)
+	require.NoError(t, err)
+
+	return &moveResponse
+}
+
+func deleteTeam(t *testing.T, query url.Values, expectedStatus int) *TeamDeletionResponse {
+	baseURL := getBaseURL()
+
+	req, err := http.NewRequest(http.MethodDelete, baseURL+"/team?"+query.Encode(), nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, expectedStatus, resp.StatusCode, "Unexpected status deleting team")
+
+	var report TeamDeletionResponse
+	err = json.NewDecoder(resp.Body).Decode(&report)
+	require.NoError(t, err)
+
+	return &report
 }
 
 func getTeam(t *testing.T, teamName string) *TeamResponse {
@@ -198,6 +423,178 @@ func mergePR(t *testing.T, prID string) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode, "Failed to merge PR")
 }
 
+func topUpReviewers(t *testing.T, prID string) *PRResponse {
+	baseURL := getBaseURL()
+	req := map[string]interface{}{
+		"pull_request_id": prID,
+	}
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	resp, err := http.Post(baseURL+"/pullRequest/topUpReviewers", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Failed to top up reviewers")
+
+	var prResponse PRResponse
+	err = json.NewDecoder(resp.Body).Decode(&prResponse)
+	require.NoError(t, err)
+
+	return &prResponse
+}
+
+func changePRStatus(t *testing.T, action, prID string, expectedStatus int) *PRResponse {
+	baseURL := getBaseURL()
+	req := map[string]interface{}{
+		"pull_request_id": prID,
+	}
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	resp, err := http.Post(baseURL+"/pullRequest/"+action, "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, expectedStatus, resp.StatusCode, "Unexpected status for %s", action)
+
+	var prResponse PRResponse
+	err = json.NewDecoder(resp.Body).Decode(&prResponse)
+	require.NoError(t, err)
+
+	return &prResponse
+}
+
+func getPR(t *testing.T, prID string) *PRResponse {
+	baseURL := getBaseURL()
+	resp, err := http.Get(baseURL + "/pullRequest/get?pull_request_id=" + url.QueryEscape(prID))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Failed to get PR")
+
+	var prResponse PRResponse
+	err = json.NewDecoder(resp.Body).Decode(&prResponse)
+	require.NoError(t, err)
+
+	return &prResponse
+}
+
+func listPRs(t *testing.T, query url.Values) *PRListResponse {
+	baseURL := getBaseURL()
+	resp, err := http.Get(baseURL + "/pullRequest/list?" + query.Encode())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Failed to list PRs")
+
+	var listResponse PRListResponse
+	err = json.NewDecoder(resp.Body).Decode(&listResponse)
+	require.NoError(t, err)
+
+	return &listResponse
+}
+
+func submitReview(t *testing.T, prID, reviewerID, state string, expectedStatus int) *PRResponse {
+	baseURL := getBaseURL()
+	req := map[string]interface{}{
+		"pull_request_id": prID,
+		"reviewer_id":     reviewerID,
+		"state":           state,
+	}
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	resp, err := http.Post(baseURL+"/pullRequest/review", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, expectedStatus, resp.StatusCode, "Unexpected status for review")
+
+	var prResponse PRResponse
+	err = json.NewDecoder(resp.Body).Decode(&prResponse)
+	require.NoError(t, err)
+
+	return &prResponse
+}
+
+func getHistory(t *testing.T, prID string) *HistoryResponse {
+	baseURL := getBaseURL()
+	resp, err := http.Get(baseURL + "/pullRequest/history?pull_request_id=" + url.QueryEscape(prID))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Failed to get assignment history")
+
+	var historyResponse HistoryResponse
+	err = json.NewDecoder(resp.Body).Decode(&historyResponse)
+	require.NoError(t, err)
+
+	return &historyResponse
+}
+
+func getStats(t *testing.T, query url.Values) *StatsResponse {
+	baseURL := getBaseURL()
+	resp, err := http.Get(baseURL + "/stats?" + query.Encode())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Failed to get stats")
+
+	var statsResponse StatsResponse
+	err = json.NewDecoder(resp.Body).Decode(&statsResponse)
+	require.NoError(t, err)
+
+	return &statsResponse
+}
+
 func generateUniqueID(prefix string) string {
 	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
 }
+
+func contains(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
+
+func postWithIdempotencyKey(t *testing.T, path, key string, payload interface{}) (*http.Response, []byte) {
+	baseURL := getBaseURL()
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, baseURL+path, bytes.NewBuffer(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp, respBody
+}
+
+func getUserReviews(t *testing.T, userID string) *PRListResponse {
+	baseURL := getBaseURL()
+	resp, err := http.Get(baseURL + "/users/getReview?user_id=" + url.QueryEscape(userID))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Failed to get user reviews")
+
+	var listResponse PRListResponse
+	err = json.NewDecoder(resp.Body).Decode(&listResponse)
+	require.NoError(t, err)
+
+	return &listResponse
+}
@@ -1,23 +1,60 @@
 package config
 
 import (
+	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/caarlos0/env/v9"
+	"github.com/nikitaenmi/AvitoTest/internal/logging"
+)
+
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
+	Storage     string `env:"STORAGE" envDefault:"postgres"`
+	Database    DatabaseConfig
+	Server      ServerConfig
+	Idempotency IdempotencyConfig
+	Auth        AuthConfig
+	Tracing     TracingConfig
+	Log         LogConfig
+	Health      HealthConfig
 }
 
 type DatabaseConfig struct {
-	Host     string `env:"DATABASE_HOST,required"`
-	Port     string `env:"DATABASE_PORT,required"`
-	User     string `env:"DATABASE_USER,required"`
-	Password string `env:"DATABASE_PASSWORD,required"`
-	Name     string `env:"DATABASE_NAME,required"`
-	SSLMode  string `env:"DATABASE_SSL_MODE,required"`
+	Host     string `env:"DATABASE_HOST"`
+	Port     string `env:"DATABASE_PORT"`
+	User     string `env:"DATABASE_USER"`
+	Password string `env:"DATABASE_PASSWORD"`
+	Name     string `env:"DATABASE_NAME"`
+	SSLMode  string `env:"DATABASE_SSL_MODE"`
+
+	MigrateOnStart bool `env:"DATABASE_MIGRATE_ON_START" envDefault:"true"`
+
+	// SlowQueryThreshold is the duration after which a query is logged as a
+	// warning; zero disables slow-query logging.
+	SlowQueryThreshold time.Duration `env:"DATABASE_SLOW_QUERY_THRESHOLD" envDefault:"200ms"`
+
+	MaxOpenConns    int           `env:"DATABASE_MAX_OPEN_CONNS" envDefault:"20"`
+	MaxIdleConns    int           `env:"DATABASE_MAX_IDLE_CONNS" envDefault:"10"`
+	ConnMaxLifetime time.Duration `env:"DATABASE_CONN_MAX_LIFETIME" envDefault:"30m"`
+	// StatementTimeout is passed to PostgreSQL as statement_timeout; zero
+	// leaves the server default.
+	StatementTimeout time.Duration `env:"DATABASE_STATEMENT_TIMEOUT" envDefault:"30s"`
+
+	ConnectInitialBackoff time.Duration `env:"DATABASE_CONNECT_INITIAL_BACKOFF" envDefault:"500ms"`
+	ConnectMaxBackoff     time.Duration `env:"DATABASE_CONNECT_MAX_BACKOFF" envDefault:"10s"`
+	ConnectMaxAttempts    int           `env:"DATABASE_CONNECT_MAX_ATTEMPTS" envDefault:"10"`
 }
 
 type ServerConfig struct {
@@ -26,6 +63,40 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `env:"SERVER_READ_TIMEOUT,required"`
 	WriteTimeout time.Duration `env:"SERVER_WRITE_TIMEOUT,required"`
 	IdleTimeout  time.Duration `env:"SERVER_IDLE_TIMEOUT,required"`
+
+	// ShutdownDelay is how long the server keeps serving after a stop signal
+	// with /readyz failing, so load balancers can take it out of rotation.
+	ShutdownDelay time.Duration `env:"SERVER_SHUTDOWN_DELAY" envDefault:"0s"`
+	// ShutdownTimeout bounds how long in-flight requests may take to finish.
+	ShutdownTimeout time.Duration `env:"SERVER_SHUTDOWN_TIMEOUT" envDefault:"30s"`
+}
+
+type IdempotencyConfig struct {
+	TTL time.Duration `env:"IDEMPOTENCY_TTL" envDefault:"24h"`
+}
+
+type AuthConfig struct {
+	// BootstrapToken, when set, is registered as an admin token on start so a
+	// fresh deployment can be used before any token is created with the CLI.
+	BootstrapToken string `env:"AUTH_BOOTSTRAP_TOKEN"`
+}
+
+type TracingConfig struct {
+	Exporter     string `env:"TRACING_EXPORTER" envDefault:"none"`
+	OTLPEndpoint string `env:"TRACING_OTLP_ENDPOINT" envDefault:"http://localhost:4318"`
+	ServiceName  string `env:"TRACING_SERVICE_NAME" envDefault:"pr-review-service"`
+}
+
+type LogConfig struct {
+	Level  slog.Level `env:"LOG_LEVEL" envDefault:"info"`
+	Format string     `env:"LOG_FORMAT" envDefault:"json"`
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+	// MaxPoolSaturation is the share of DATABASE_MAX_OPEN_CONNS in use above
+	// which the instance reports itself not ready.
+	MaxPoolSaturation float64 `env:"HEALTH_MAX_POOL_SATURATION" envDefault:"0.9"`
 }
 
 func Load() (*Config, error) {
@@ -33,5 +104,74 @@ func Load() (*Config, error) {
 	if err := env.Parse(cfg); err != nil {
 		return nil, err
 	}
+
+	switch cfg.Storage {
+	case StorageMemory:
+	case StoragePostgres:
+		if err := cfg.Database.validate(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown STORAGE %q, expected %s or %s", cfg.Storage, StoragePostgres, StorageMemory)
+	}
+
+	switch cfg.Log.Format {
+	case logging.FormatJSON, logging.FormatText:
+	default:
+		return nil, fmt.Errorf("unknown LOG_FORMAT %q, expected %s or %s",
+			cfg.Log.Format, logging.FormatJSON, logging.FormatText)
+	}
+
+	switch cfg.Tracing.Exporter {
+	case TracingExporterNone, TracingExporterStdout, TracingExporterOTLP:
+	default:
+		return nil, fmt.Errorf("unknown TRACING_EXPORTER %q, expected %s, %s or %s",
+			cfg.Tracing.Exporter, TracingExporterNone, TracingExporterStdout, TracingExporterOTLP)
+	}
+
+	if cfg.Idempotency.TTL <= 0 {
+		return nil, fmt.Errorf("IDEMPOTENCY_TTL must be positive, got %s", cfg.Idempotency.TTL)
+	}
+
+	if cfg.Server.ShutdownDelay < 0 || cfg.Server.ShutdownTimeout <= 0 {
+		return nil, fmt.Errorf("SERVER_SHUTDOWN_DELAY must not be negative and SERVER_SHUTDOWN_TIMEOUT must be positive")
+	}
+
+	if cfg.Health.CheckTimeout <= 0 {
+		return nil, fmt.Errorf("HEALTH_CHECK_TIMEOUT must be positive, got %s", cfg.Health.CheckTimeout)
+	}
+	if cfg.Health.MaxPoolSaturation <= 0 || cfg.Health.MaxPoolSaturation > 1 {
+		return nil, fmt.Errorf("HEALTH_MAX_POOL_SATURATION must be in (0, 1], got %v", cfg.Health.MaxPoolSaturation)
+	}
+
 	return cfg, nil
 }
+
+func (c DatabaseConfig) validate() error {
+	for _, v := range []struct{ name, value string }{
+		{"DATABASE_HOST", c.Host},
+		{"DATABASE_PORT", c.Port},
+		{"DATABASE_USER", c.User},
+		{"DATABASE_PASSWORD", c.Password},
+		{"DATABASE_NAME", c.Name},
+		{"DATABASE_SSL_MODE", c.SSLMode},
+	} {
+		if v.value == "" {
+			return fmt.Errorf("required environment variable %q is not set", v.name)
+		}
+	}
+
+	switch {
+	case c.MaxOpenConns <= 0:
+		return fmt.Errorf("DATABASE_MAX_OPEN_CONNS must be positive, got %d", c.MaxOpenConns)
+	case c.MaxIdleConns < 0 || c.MaxIdleConns > c.MaxOpenConns:
+		return fmt.Errorf("DATABASE_MAX_IDLE_CONNS must be between 0 and DATABASE_MAX_OPEN_CONNS, got %d", c.MaxIdleConns)
+	case c.ConnMaxLifetime < 0 || c.StatementTimeout < 0:
+		return fmt.Errorf("DATABASE_CONN_MAX_LIFETIME and DATABASE_STATEMENT_TIMEOUT must not be negative")
+	case c.ConnectInitialBackoff <= 0 || c.ConnectMaxBackoff < c.ConnectInitialBackoff:
+		return fmt.Errorf("DATABASE_CONNECT_INITIAL_BACKOFF must be positive and not exceed DATABASE_CONNECT_MAX_BACKOFF")
+	case c.ConnectMaxAttempts <= 0:
+		return fmt.Errorf("DATABASE_CONNECT_MAX_ATTEMPTS must be positive, got %d", c.ConnectMaxAttempts)
+	}
+	return nil
+}
@@ -1,30 +1,81 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"time"
 
-	"github.com/nikitaenmi/AvitoTest/internal/database/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
-func NewPostgresDB(dsn string) (*gorm.DB, error) {
+type PoolOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+func NewPostgresDB(dsn string, pool PoolOptions, slowQueryThreshold time.Duration) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger:         NewGormLogger(slowQueryThreshold),
+		TranslateError: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	err = db.AutoMigrate(
-		&models.Team{},
-		&models.User{},
-		&models.PullRequest{},
-	)
+	sqlDB, err := db.DB()
 	if err != nil {
-		return nil, fmt.Errorf("failed to auto migrate: %w", err)
+		return nil, err
 	}
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
 
 	return db, nil
 }
+
+// Backoff describes exponentially growing delays between connection
+// attempts: Initial, doubled after every failure and capped at Max.
+type Backoff struct {
+	Initial     time.Duration
+	Max         time.Duration
+	MaxAttempts int
+}
+
+// Delay returns how long to wait after the given failed attempt, counting
+// from 1.
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Initial
+	for i := 1; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	return min(delay, b.Max)
+}
+
+// ConnectWithRetry calls connect until it succeeds, the attempts run out or
+// ctx is cancelled, and returns the last connection error.
+func ConnectWithRetry(ctx context.Context, backoff Backoff, connect func() (*gorm.DB, error)) (*gorm.DB, error) {
+	for attempt := 1; ; attempt++ {
+		db, err := connect()
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= backoff.MaxAttempts {
+			return nil, err
+		}
+
+		delay := backoff.Delay(attempt)
+		slog.Warn("failed to connect to database",
+			slog.Int("attempt", attempt), slog.Duration("retry_in", delay), slog.Any("error", err))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
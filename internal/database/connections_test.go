@@ -0,0 +1,66 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	backoff := database.Backoff{Initial: 500 * time.Millisecond, Max: 3 * time.Second}
+
+	assert.Equal(t, 500*time.Millisecond, backoff.Delay(1))
+	assert.Equal(t, time.Second, backoff.Delay(2))
+	assert.Equal(t, 2*time.Second, backoff.Delay(3))
+	assert.Equal(t, 3*time.Second, backoff.Delay(4))
+	assert.Equal(t, 3*time.Second, backoff.Delay(50))
+}
+
+func TestConnectWithRetry(t *testing.T) {
+	backoff := database.Backoff{Initial: time.Millisecond, Max: time.Millisecond, MaxAttempts: 3}
+	errRefused := errors.New("connection refused")
+
+	t.Run("succeeds after failures", func(t *testing.T) {
+		attempts := 0
+		db, err := database.ConnectWithRetry(context.Background(), backoff, func() (*gorm.DB, error) {
+			attempts++
+			if attempts < 3 {
+				return nil, errRefused
+			}
+			return &gorm.DB{}, nil
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, db)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		attempts := 0
+		_, err := database.ConnectWithRetry(context.Background(), backoff, func() (*gorm.DB, error) {
+			attempts++
+			return nil, errRefused
+		})
+		assert.ErrorIs(t, err, errRefused)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("stops when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		slow := database.Backoff{Initial: time.Hour, Max: time.Hour, MaxAttempts: 3}
+		attempts := 0
+		_, err := database.ConnectWithRetry(ctx, slow, func() (*gorm.DB, error) {
+			attempts++
+			cancel()
+			return nil, errRefused
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, err, errRefused)
+		assert.Equal(t, 1, attempts)
+	})
+}
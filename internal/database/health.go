@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/nikitaenmi/AvitoTest/internal/health"
+	"github.com/nikitaenmi/AvitoTest/internal/logging"
+)
+
+// The readiness response is public, so the checks below log the driver error
+// and report a fixed message instead.
+
+func PingCheck(db *sql.DB) health.Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		if err := db.PingContext(ctx); err != nil {
+			logging.FromContext(ctx).Warn("database ping failed", slog.Any("error", err))
+			return nil, errors.New("database is unreachable")
+		}
+		return nil, nil
+	}
+}
+
+func MigrationsCheck(migrator *Migrator) health.Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			logging.FromContext(ctx).Warn("failed to read applied migrations", slog.Any("error", err))
+			return nil, errors.New("failed to read applied migrations")
+		}
+
+		details := map[string]any{"pending": pending}
+		if len(pending) > 0 {
+			return details, fmt.Errorf("%d migrations are not applied", len(pending))
+		}
+		return details, nil
+	}
+}
+
+// PoolCheck fails when more than maxSaturation of the allowed open
+// connections are in use. An unlimited pool is never saturated.
+func PoolCheck(db *sql.DB, maxSaturation float64) health.Check {
+	return func(context.Context) (map[string]any, error) {
+		stats := db.Stats()
+		details := map[string]any{
+			"open":       stats.OpenConnections,
+			"in_use":     stats.InUse,
+			"idle":       stats.Idle,
+			"max_open":   stats.MaxOpenConnections,
+			"wait_count": stats.WaitCount,
+		}
+		if stats.MaxOpenConnections <= 0 {
+			return details, nil
+		}
+
+		saturation := float64(stats.InUse) / float64(stats.MaxOpenConnections)
+		details["saturation"] = saturation
+		if saturation > maxSaturation {
+			return details, fmt.Errorf("connection pool is %.0f%% saturated", saturation*100)
+		}
+		return details, nil
+	}
+}
@@ -0,0 +1,50 @@
+package database_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/database"
+	"github.com/nikitaenmi/AvitoTest/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestGormLogger(t *testing.T) {
+	var buf bytes.Buffer
+	scoped := logging.New(&buf, slog.LevelInfo, logging.FormatJSON).With(slog.String("request_id", "req-1"))
+	ctx := logging.WithLogger(context.Background(), scoped)
+	query := func() (string, int64) { return `SELECT * FROM "teams"`, 3 }
+
+	gormLogger := database.NewGormLogger(100 * time.Millisecond)
+	gormLogger.Trace(ctx, time.Now(), query, nil)
+	gormLogger.Trace(ctx, time.Now(), query, gorm.ErrRecordNotFound)
+	gormLogger.Trace(ctx, time.Now().Add(-time.Second), query, nil)
+	gormLogger.Trace(ctx, time.Now(), query, errors.New("connection refused"))
+	gormLogger.LogMode(logger.Silent).Trace(ctx, time.Now(), query, errors.New("ignored"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var slow, failed map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &slow))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &failed))
+
+	assert.Equal(t, "WARN", slow["level"])
+	assert.Equal(t, "slow query", slow["msg"])
+	assert.Equal(t, `SELECT * FROM "teams"`, slow["sql"])
+	assert.Equal(t, float64(3), slow["rows"])
+	assert.Equal(t, "req-1", slow["request_id"])
+
+	assert.Equal(t, "ERROR", failed["level"])
+	assert.Equal(t, "query failed", failed["msg"])
+	assert.Equal(t, "connection refused", failed["error"])
+}
@@ -4,44 +4,109 @@ import (
 	"time"
 
 	"github.com/nikitaenmi/AvitoTest/internal/domain"
+	"gorm.io/gorm"
 )
 
 type Team struct {
-	TeamName string `gorm:"primaryKey" json:"team_name"`
+	TeamName          string `gorm:"primaryKey" json:"team_name"`
+	RequiredReviewers int    `gorm:"not null;default:2" json:"required_reviewers"`
+	RequiredApprovals int    `gorm:"not null;default:0" json:"required_approvals"`
+
+	ArchivedAt gorm.DeletedAt `gorm:"column:archived_at;index" json:"archivedAt,omitempty"`
+}
+
+type TeamFallback struct {
+	TeamName         string `gorm:"primaryKey" json:"team_name"`
+	FallbackTeamName string `gorm:"primaryKey" json:"fallback_team_name"`
+	Priority         int    `json:"priority"`
 }
 
 type User struct {
-	UserID   string `gorm:"primaryKey" json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID   string  `gorm:"primaryKey" json:"user_id"`
+	Username string  `json:"username"`
+	TeamName *string `json:"team_name"`
+	IsActive bool    `json:"is_active"`
+	Role     string  `gorm:"size:20;not null;default:MEMBER" json:"role"`
 }
 
 type PullRequest struct {
-	PullRequestID     string     `gorm:"primaryKey" json:"pull_request_id"`
-	PullRequestName   string     `json:"pull_request_name"`
-	AuthorID          string     `json:"author_id"`
-	Status            string     `json:"status"`
-	AssignedReviewers []string   `gorm:"type:jsonb;serializer:json" json:"assigned_reviewers"`
-	CreatedAt         *time.Time `json:"createdAt,omitempty"`
-	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	PullRequestID   string     `gorm:"primaryKey" json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+	AuthorID        string     `json:"author_id"`
+	Status          string     `json:"status"`
+	CreatedAt       *time.Time `json:"createdAt,omitempty"`
+	MergedAt        *time.Time `json:"mergedAt,omitempty"`
+	ClosedAt        *time.Time `json:"closedAt,omitempty"`
+	MergedBy        *string    `json:"mergedBy,omitempty"`
+	Version         int64      `gorm:"not null;default:1" json:"-"`
+}
+
+type PRReviewer struct {
+	PullRequestID string    `gorm:"primaryKey" json:"pull_request_id"`
+	UserID        string    `gorm:"primaryKey;index" json:"user_id"`
+	Slot          int       `gorm:"not null" json:"slot"`
+	IsFallback    bool      `gorm:"not null;default:false" json:"is_fallback"`
+	AssignedAt    time.Time `gorm:"not null" json:"assignedAt"`
+}
+
+type Review struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	PullRequestID string    `gorm:"not null" json:"pull_request_id"`
+	ReviewerID    string    `gorm:"not null" json:"reviewer_id"`
+	State         string    `gorm:"not null" json:"state"`
+	Comment       string    `json:"comment"`
+	SubmittedAt   time.Time `gorm:"not null" json:"submittedAt"`
+}
+
+type ReviewerAssignment struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	PullRequestID string    `gorm:"not null" json:"pull_request_id"`
+	UserID        string    `gorm:"not null" json:"user_id"`
+	Action        string    `gorm:"not null" json:"action"`
+	Operation     string    `gorm:"not null" json:"operation"`
+	Reason        string    `json:"reason"`
+	CreatedAt     time.Time `gorm:"not null" json:"createdAt"`
+}
+
+type APIToken struct {
+	ID        int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string     `gorm:"not null" json:"name"`
+	Role      string     `gorm:"size:20;not null" json:"role"`
+	UserID    *string    `json:"user_id"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	CreatedAt time.Time  `gorm:"not null" json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+type IdempotencyKey struct {
+	IdempotencyKey string    `gorm:"primaryKey;size:255" json:"idempotency_key"`
+	RequestHash    string    `gorm:"size:64;not null" json:"request_hash"`
+	StatusCode     int       `gorm:"not null;default:0" json:"status_code"`
+	ContentType    string    `json:"content_type"`
+	ResponseBody   []byte    `json:"response_body"`
+	CreatedAt      time.Time `gorm:"not null" json:"createdAt"`
+	ExpiresAt      time.Time `gorm:"not null;index" json:"expiresAt"`
 }
 
 func UserToDomain(m User) domain.User {
 	return domain.User{
 		UserID:   m.UserID,
 		Username: m.Username,
-		TeamName: m.TeamName,
+		TeamName: stringValue(m.TeamName),
 		IsActive: m.IsActive,
+		Role:     m.Role,
 	}
 }
 
+// UserFromDomain stores an empty team name as NULL, which is how removed
+// team members are kept for the pull requests and reviews that reference them.
 func UserFromDomain(d domain.User) User {
 	return User{
 		UserID:   d.UserID,
 		Username: d.Username,
-		TeamName: d.TeamName,
+		TeamName: optionalString(d.TeamName),
 		IsActive: d.IsActive,
+		Role:     d.Role,
 	}
 }
 
@@ -53,47 +118,218 @@ func UsersToDomain(models []User) []domain.User {
 	return domainUsers
 }
 
-func TeamToDomain(m Team, members []domain.User) domain.Team {
-	return domain.Team{
-		TeamName: m.TeamName,
-		Members:  members,
+func TeamToDomain(m Team, fallbackTeams []string, members []domain.User) domain.Team {
+	team := domain.Team{
+		TeamName:          m.TeamName,
+		RequiredReviewers: m.RequiredReviewers,
+		RequiredApprovals: m.RequiredApprovals,
+		FallbackTeams:     fallbackTeams,
+		Members:           members,
+	}
+	if m.ArchivedAt.Valid {
+		team.ArchivedAt = &m.ArchivedAt.Time
 	}
+	return team
 }
 
 func TeamFromDomain(d domain.Team) Team {
 	return Team{
-		TeamName: d.TeamName,
+		TeamName:          d.TeamName,
+		RequiredReviewers: d.RequiredReviewers,
+		RequiredApprovals: d.RequiredApprovals,
 	}
 }
 
-func PullRequestToDomain(m PullRequest) domain.PullRequest {
+func PullRequestToDomain(m PullRequest, reviewers []PRReviewer) domain.PullRequest {
+	assigned := make([]string, 0, len(reviewers))
+	var fallback []string
+	for _, reviewer := range reviewers {
+		assigned = append(assigned, reviewer.UserID)
+		if reviewer.IsFallback {
+			fallback = append(fallback, reviewer.UserID)
+		}
+	}
+
 	return domain.PullRequest{
 		PullRequestID:     m.PullRequestID,
 		PullRequestName:   m.PullRequestName,
 		AuthorID:          m.AuthorID,
 		Status:            m.Status,
-		AssignedReviewers: m.AssignedReviewers,
+		AssignedReviewers: assigned,
+		FallbackReviewers: fallback,
 		CreatedAt:         m.CreatedAt,
 		MergedAt:          m.MergedAt,
+		ClosedAt:          m.ClosedAt,
+		MergedBy:          stringValue(m.MergedBy),
+		Version:           m.Version,
 	}
 }
 
 func PullRequestFromDomain(d domain.PullRequest) PullRequest {
 	return PullRequest{
-		PullRequestID:     d.PullRequestID,
-		PullRequestName:   d.PullRequestName,
-		AuthorID:          d.AuthorID,
-		Status:            d.Status,
-		AssignedReviewers: d.AssignedReviewers,
-		CreatedAt:         d.CreatedAt,
-		MergedAt:          d.MergedAt,
+		PullRequestID:   d.PullRequestID,
+		PullRequestName: d.PullRequestName,
+		AuthorID:        d.AuthorID,
+		Status:          d.Status,
+		CreatedAt:       d.CreatedAt,
+		MergedAt:        d.MergedAt,
+		ClosedAt:        d.ClosedAt,
+		MergedBy:        optionalString(d.MergedBy),
+		Version:         d.Version,
 	}
 }
 
-func PullRequestsToDomain(models []PullRequest) []domain.PullRequest {
+func PRReviewersFromDomain(d domain.PullRequest, assignedAt time.Time) []PRReviewer {
+	fallback := make(map[string]bool, len(d.FallbackReviewers))
+	for _, userID := range d.FallbackReviewers {
+		fallback[userID] = true
+	}
+
+	reviewers := make([]PRReviewer, len(d.AssignedReviewers))
+	for i, userID := range d.AssignedReviewers {
+		reviewers[i] = PRReviewer{
+			PullRequestID: d.PullRequestID,
+			UserID:        userID,
+			Slot:          i,
+			IsFallback:    fallback[userID],
+			AssignedAt:    assignedAt,
+		}
+	}
+	return reviewers
+}
+
+func PullRequestsToDomain(models []PullRequest, reviewers map[string][]PRReviewer) []domain.PullRequest {
 	domainPRs := make([]domain.PullRequest, len(models))
 	for i, model := range models {
-		domainPRs[i] = PullRequestToDomain(model)
+		domainPRs[i] = PullRequestToDomain(model, reviewers[model.PullRequestID])
 	}
 	return domainPRs
 }
+
+func ReviewToDomain(m Review) domain.Review {
+	return domain.Review{
+		PullRequestID: m.PullRequestID,
+		ReviewerID:    m.ReviewerID,
+		State:         m.State,
+		Comment:       m.Comment,
+		SubmittedAt:   m.SubmittedAt,
+	}
+}
+
+func ReviewFromDomain(d domain.Review) Review {
+	return Review{
+		PullRequestID: d.PullRequestID,
+		ReviewerID:    d.ReviewerID,
+		State:         d.State,
+		Comment:       d.Comment,
+		SubmittedAt:   d.SubmittedAt,
+	}
+}
+
+func ReviewsToDomain(models []Review) []domain.Review {
+	domainReviews := make([]domain.Review, len(models))
+	for i, model := range models {
+		domainReviews[i] = ReviewToDomain(model)
+	}
+	return domainReviews
+}
+
+func ReviewerAssignmentToDomain(m ReviewerAssignment) domain.ReviewerAssignment {
+	return domain.ReviewerAssignment{
+		PullRequestID: m.PullRequestID,
+		UserID:        m.UserID,
+		Action:        m.Action,
+		Operation:     m.Operation,
+		Reason:        m.Reason,
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+func ReviewerAssignmentFromDomain(d domain.ReviewerAssignment) ReviewerAssignment {
+	return ReviewerAssignment{
+		PullRequestID: d.PullRequestID,
+		UserID:        d.UserID,
+		Action:        d.Action,
+		Operation:     d.Operation,
+		Reason:        d.Reason,
+		CreatedAt:     d.CreatedAt,
+	}
+}
+
+func ReviewerAssignmentsToDomain(models []ReviewerAssignment) []domain.ReviewerAssignment {
+	domainAssignments := make([]domain.ReviewerAssignment, len(models))
+	for i, model := range models {
+		domainAssignments[i] = ReviewerAssignmentToDomain(model)
+	}
+	return domainAssignments
+}
+
+func IdempotencyKeyToDomain(m IdempotencyKey) domain.IdempotencyRecord {
+	return domain.IdempotencyRecord{
+		Key:          m.IdempotencyKey,
+		RequestHash:  m.RequestHash,
+		StatusCode:   m.StatusCode,
+		ContentType:  m.ContentType,
+		ResponseBody: m.ResponseBody,
+		CreatedAt:    m.CreatedAt,
+		ExpiresAt:    m.ExpiresAt,
+	}
+}
+
+func IdempotencyKeyFromDomain(d domain.IdempotencyRecord) IdempotencyKey {
+	return IdempotencyKey{
+		IdempotencyKey: d.Key,
+		RequestHash:    d.RequestHash,
+		StatusCode:     d.StatusCode,
+		ContentType:    d.ContentType,
+		ResponseBody:   d.ResponseBody,
+		CreatedAt:      d.CreatedAt,
+		ExpiresAt:      d.ExpiresAt,
+	}
+}
+
+func APITokenToDomain(m APIToken) domain.APIToken {
+	return domain.APIToken{
+		ID:        m.ID,
+		Name:      m.Name,
+		Role:      m.Role,
+		UserID:    stringValue(m.UserID),
+		TokenHash: m.TokenHash,
+		CreatedAt: m.CreatedAt,
+		RevokedAt: m.RevokedAt,
+	}
+}
+
+func APITokenFromDomain(d domain.APIToken) APIToken {
+	return APIToken{
+		ID:        d.ID,
+		Name:      d.Name,
+		Role:      d.Role,
+		UserID:    optionalString(d.UserID),
+		TokenHash: d.TokenHash,
+		CreatedAt: d.CreatedAt,
+		RevokedAt: d.RevokedAt,
+	}
+}
+
+func APITokensToDomain(models []APIToken) []domain.APIToken {
+	tokens := make([]domain.APIToken, len(models))
+	for i, model := range models {
+		tokens[i] = APITokenToDomain(model)
+	}
+	return tokens
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
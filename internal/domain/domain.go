@@ -6,8 +6,12 @@ import (
 )
 
 type Team struct {
-	TeamName string `json:"team_name"`
-	Members  []User `json:"members"`
+	TeamName          string     `json:"team_name"`
+	RequiredReviewers int        `json:"required_reviewers"`
+	RequiredApprovals int        `json:"required_approvals"`
+	FallbackTeams     []string   `json:"fallback_teams,omitempty"`
+	Members           []User     `json:"members"`
+	ArchivedAt        *time.Time `json:"archived_at,omitempty"`
 }
 
 type User struct {
@@ -15,16 +19,138 @@ type User struct {
 	Username string `json:"username"`
 	TeamName string `json:"team_name"`
 	IsActive bool   `json:"is_active"`
+	Role     string `json:"role"`
 }
 
 type PullRequest struct {
-	PullRequestID     string     `json:"pull_request_id"`
-	PullRequestName   string     `json:"pull_request_name"`
-	AuthorID          string     `json:"author_id"`
-	Status            string     `json:"status"`
-	AssignedReviewers []string   `json:"assigned_reviewers"`
-	CreatedAt         *time.Time `json:"createdAt,omitempty"`
-	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	PullRequestID     string          `json:"pull_request_id"`
+	PullRequestName   string          `json:"pull_request_name"`
+	AuthorID          string          `json:"author_id"`
+	Status            string          `json:"status"`
+	AssignedReviewers []string        `json:"assigned_reviewers"`
+	FallbackReviewers []string        `json:"fallback_reviewers,omitempty"`
+	CreatedAt         *time.Time      `json:"createdAt,omitempty"`
+	MergedAt          *time.Time      `json:"mergedAt,omitempty"`
+	ClosedAt          *time.Time      `json:"closedAt,omitempty"`
+	MergedBy          string          `json:"mergedBy,omitempty"`
+	Reviews           []ReviewerState `json:"reviews,omitempty"`
+	Version           int64           `json:"-"`
+}
+
+type Review struct {
+	PullRequestID string    `json:"pull_request_id"`
+	ReviewerID    string    `json:"reviewer_id"`
+	State         string    `json:"state"`
+	Comment       string    `json:"comment,omitempty"`
+	SubmittedAt   time.Time `json:"submittedAt"`
+}
+
+type ReviewerState struct {
+	ReviewerID  string     `json:"reviewer_id"`
+	State       string     `json:"state"`
+	SubmittedAt *time.Time `json:"submittedAt,omitempty"`
+}
+
+type ReviewerAssignment struct {
+	PullRequestID string    `json:"pull_request_id"`
+	UserID        string    `json:"user_id"`
+	Action        string    `json:"action"`
+	Operation     string    `json:"operation"`
+	Reason        string    `json:"reason,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type ReviewerReplacement struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id,omitempty"`
+}
+
+type DeactivationReport struct {
+	DeactivatedUsers []string              `json:"deactivated_users"`
+	Reassigned       []ReviewerReplacement `json:"reassigned"`
+	NoCandidate      []ReviewerReplacement `json:"no_candidate"`
+}
+
+type APIToken struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	UserID    string     `json:"user_id,omitempty"`
+	TokenHash string     `json:"-"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// Caller is the authenticated client of a request.
+type Caller struct {
+	TokenID int64
+	Name    string
+	Role    string
+	UserID  string
+}
+
+// Identity names the caller in audit fields such as merged_by: the user the
+// token belongs to, or the token name for service tokens.
+func (c Caller) Identity() string {
+	if c.UserID != "" {
+		return c.UserID
+	}
+	return "token:" + c.Name
+}
+
+type TeamDeleteOptions struct {
+	Hard         bool
+	CloseOpenPRs bool
+}
+
+type TeamDeletionReport struct {
+	TeamName           string   `json:"team_name"`
+	Archived           bool     `json:"archived"`
+	ClosedPullRequests []string `json:"closed_pull_requests"`
+	DeactivationReport
+}
+
+type UserAssignmentStats struct {
+	UserID             string `json:"user_id"`
+	TeamName           string `json:"team_name"`
+	CurrentAssignments int    `json:"current_assignments"`
+	TotalAssignments   int    `json:"total_assignments"`
+}
+
+type TeamPRStats struct {
+	TeamName string `json:"team_name"`
+	OpenPRs  int    `json:"open_prs"`
+}
+
+type PRReviewerStats struct {
+	PullRequestID string `json:"pull_request_id"`
+	Status        string `json:"status"`
+	Reviewers     int    `json:"reviewers"`
+}
+
+type Stats struct {
+	Users                     []UserAssignmentStats `json:"users"`
+	Teams                     []TeamPRStats         `json:"teams"`
+	PullRequests              []PRReviewerStats     `json:"pull_requests"`
+	AverageTimeToMergeSeconds *float64              `json:"average_time_to_merge_seconds"`
+}
+
+// IdempotencyRecord stores the response to a POST request made with an
+// Idempotency-Key header. StatusCode is zero while the request is in flight.
+type IdempotencyRecord struct {
+	Key          string
+	RequestHash  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+type ReviewerCandidate struct {
+	User        User
+	OpenReviews int
 }
 
 type UserRepository interface {
@@ -32,30 +158,114 @@ type UserRepository interface {
 	FindOne(ctx context.Context, filter UserFilter) (*User, error)
 	Update(ctx context.Context, user *User) error
 	FindAll(ctx context.Context, filter UserFilter) ([]User, error)
+	SetActive(ctx context.Context, userIDs []string, isActive bool) error
 }
 
 type TeamRepository interface {
 	Create(ctx context.Context, team Team) error
 	FindOne(ctx context.Context, filter TeamFilter) (*Team, error)
 	Exists(ctx context.Context, filter TeamFilter) (bool, error)
+	SetFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) error
+	FindFallbackTeams(ctx context.Context, teamName string) ([]string, error)
+	LockMembers(ctx context.Context, teamName string) error
+	HasPullRequests(ctx context.Context, teamName string) (bool, error)
+	Archive(ctx context.Context, teamName string) error
+	Delete(ctx context.Context, teamName string) error
 }
 
 type PRRepository interface {
 	Create(ctx context.Context, pr PullRequest) error
 	FindOne(ctx context.Context, filter PRFilter) (*PullRequest, error)
 	Update(ctx context.Context, pr *PullRequest) error
+	UpdateReviewers(ctx context.Context, prs []PullRequest) error
 	FindAll(ctx context.Context, filter PRFilter) ([]PullRequest, error)
 	Exists(ctx context.Context, filter PRFilter) (bool, error)
 	FindByReviewer(ctx context.Context, userID string) ([]PullRequest, error)
+	CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error)
+}
+
+type ReviewRepository interface {
+	Create(ctx context.Context, review Review) error
+	FindLatest(ctx context.Context, prIDs []string) ([]Review, error)
+}
+
+type AssignmentRepository interface {
+	Create(ctx context.Context, assignments []ReviewerAssignment) error
+	FindByPR(ctx context.Context, prID string) ([]ReviewerAssignment, error)
+	FindLatestAssigned(ctx context.Context, prIDs []string) ([]ReviewerAssignment, error)
+}
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, record IdempotencyRecord) (*IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error
+	Release(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type TokenRepository interface {
+	Create(ctx context.Context, token *APIToken) error
+	FindByHash(ctx context.Context, tokenHash string) (*APIToken, error)
+	FindAll(ctx context.Context) ([]APIToken, error)
+	Revoke(ctx context.Context, id int64, revokedAt time.Time) (bool, error)
+}
+
+type StatsRepository interface {
+	UserAssignments(ctx context.Context, filter StatsFilter) ([]UserAssignmentStats, error)
+	OpenPRsByTeam(ctx context.Context, filter StatsFilter) ([]TeamPRStats, error)
+	ReviewersPerPR(ctx context.Context, filter StatsFilter) ([]PRReviewerStats, error)
+	AverageTimeToMerge(ctx context.Context, filter StatsFilter) (*float64, error)
+}
+
+type Repositories struct {
+	Users       UserRepository
+	Teams       TeamRepository
+	PRs         PRRepository
+	Reviews     ReviewRepository
+	Assignments AssignmentRepository
+	Stats       StatsRepository
+	Idempotency IdempotencyRepository
+	Tokens      TokenRepository
+}
+
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error
+}
+
+type ReviewerSelector interface {
+	Select(candidates []ReviewerCandidate, count int) []User
+}
+
+// PRPolicy decides whether caller may perform action on pr. It runs inside the
+// transaction that performs the action.
+type PRPolicy interface {
+	Authorize(ctx context.Context, repos Repositories, caller Caller, action string, pr PullRequest) error
+}
+
+// EventRecorder receives business events once the transaction that produced
+// them has committed.
+type EventRecorder interface {
+	PRCreated(assignedReviewers, requiredReviewers int)
+	// PROpened reports a draft marked ready or a closed pull request reopened.
+	PROpened(assignedReviewers, requiredReviewers int)
+	PRMerged()
+	ReviewersReassigned(count int)
+	NoCandidate()
 }
 
 type TeamService interface {
 	CreateTeam(ctx context.Context, team Team) (*Team, error)
 	GetTeam(ctx context.Context, filter TeamFilter) (*Team, error)
+	SetFallbackTeams(ctx context.Context, filter TeamFilter, fallbackTeams []string) (*Team, error)
+	DeactivateTeamUsers(ctx context.Context, filter TeamFilter, userIDs []string) (*DeactivationReport, error)
+	AddTeamMembers(ctx context.Context, filter TeamFilter, members []User) (*Team, error)
+	RemoveTeamMember(ctx context.Context, filter TeamFilter, userID string) (*DeactivationReport, error)
+	DeleteTeam(ctx context.Context, filter TeamFilter, opts TeamDeleteOptions) (*TeamDeletionReport, error)
 }
 
 type UserService interface {
-	SetUserActive(ctx context.Context, filter UserFilter, isActive bool) error
+	SetUserActive(ctx context.Context, filter UserFilter, isActive bool) (*DeactivationReport, error)
+	MoveUserToTeam(ctx context.Context, filter UserFilter, teamName, username string) (*User, *DeactivationReport, error)
+	SetUserRole(ctx context.Context, filter UserFilter, role string) (*User, error)
 	GetUserReviewPRs(ctx context.Context, filter UserFilter) ([]PullRequest, error)
 	GetActiveTeamMembers(ctx context.Context, teamName string, excludeUserIDs ...string) ([]User, error)
 	GetUserByID(ctx context.Context, userID string) (*User, error)
@@ -64,34 +274,140 @@ type UserService interface {
 type PRService interface {
 	CreatePR(ctx context.Context, pr PullRequest) (*PullRequest, error)
 	MergePR(ctx context.Context, filter PRFilter) error
-	ReassignReviewer(ctx context.Context, filter PRFilter, oldReviewerID string) (string, error)
+	ClosePR(ctx context.Context, filter PRFilter) (*PullRequest, error)
+	ReopenPR(ctx context.Context, filter PRFilter) (*PullRequest, error)
+	MarkPRReady(ctx context.Context, filter PRFilter) (*PullRequest, error)
+	TopUpReviewers(ctx context.Context, filter PRFilter) (*PullRequest, error)
+	ReassignReviewer(ctx context.Context, filter PRFilter, oldReviewerID, reason string) (string, error)
+	GetAssignmentHistory(ctx context.Context, filter PRFilter) ([]ReviewerAssignment, error)
 	GetPR(ctx context.Context, filter PRFilter) (*PullRequest, error)
-	HealthCheck(ctx context.Context) error
+	ListPRs(ctx context.Context, filter PRFilter) ([]PullRequest, *PRCursor, error)
+}
+
+type ReviewService interface {
+	SubmitReview(ctx context.Context, review Review) (*PullRequest, error)
+}
+
+type StatsService interface {
+	GetStats(ctx context.Context, filter StatsFilter) (*Stats, error)
+}
+
+type AuthService interface {
+	Authenticate(ctx context.Context, secret string) (*Caller, error)
+	CreateToken(ctx context.Context, token APIToken) (string, *APIToken, error)
+	EnsureToken(ctx context.Context, token APIToken, secret string) error
+	ListTokens(ctx context.Context) ([]APIToken, error)
+	RevokeToken(ctx context.Context, id int64) error
 }
 
 type Service interface {
+	AuthService
 	TeamService
 	UserService
 	PRService
+	ReviewService
+	StatsService
 }
 
 type UserFilter struct {
 	UserID   *string
+	UserIDs  []string
 	TeamName *string
 	IsActive *bool
 }
 
 type TeamFilter struct {
-	TeamName *string
+	TeamName        *string
+	IncludeArchived bool
 }
 
 type PRFilter struct {
 	PullRequestID *string
 	AuthorID      *string
 	Status        *string
+	ReviewerID    *string
+	ReviewerIDs   []string
+	TeamName      *string
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	MergedFrom    *time.Time
+	MergedTo      *time.Time
+	After         *PRCursor
+	Limit         int
+}
+
+type StatsFilter struct {
+	TeamName *string
+	// From and To bound each figure by its own timestamp: all-time assignments
+	// by when the reviewer was assigned, time-to-merge by when the pull request
+	// was merged, and current assignments, open PRs and the per-PR breakdown by
+	// when the pull request was created.
+	From *time.Time
+	To   *time.Time
+	// Limit caps the per-PR breakdown, which lists the most recent pull
+	// requests first.
+	Limit int
+}
+
+type PRCursor struct {
+	CreatedAt     time.Time
+	PullRequestID string
 }
 
 const (
+	PRStatusDraft  = "DRAFT"
 	PRStatusOpen   = "OPEN"
 	PRStatusMerged = "MERGED"
+	PRStatusClosed = "CLOSED"
+)
+
+const (
+	ReviewStatePending          = "PENDING"
+	ReviewStateApproved         = "APPROVED"
+	ReviewStateChangesRequested = "CHANGES_REQUESTED"
+	ReviewStateCommented        = "COMMENTED"
+)
+
+const (
+	AssignmentActionAssigned   = "ASSIGNED"
+	AssignmentActionUnassigned = "UNASSIGNED"
+)
+
+const (
+	AssignmentOperationCreate     = "CREATE"
+	AssignmentOperationReassign   = "REASSIGN"
+	AssignmentOperationDeactivate = "DEACTIVATE"
+	AssignmentOperationManual     = "MANUAL"
+	AssignmentOperationMembership = "MEMBERSHIP"
+)
+
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+const (
+	UserRoleMember = "MEMBER"
+	UserRoleLead   = "LEAD"
+)
+
+const (
+	PRActionMerge    = "merge"
+	PRActionReassign = "reassign"
+	PRActionClose    = "close"
+	PRActionReopen   = "reopen"
+	PRActionReady    = "request review for"
+	PRActionTopUp    = "top up reviewers on"
+)
+
+const DefaultRequiredReviewers = 2
+
+const (
+	DefaultPRPageSize = 50
+	MaxPRPageSize     = 100
+)
+
+const (
+	DefaultStatsPRLimit = 100
+	MaxStatsPRLimit     = 1000
 )
@@ -1,17 +1,44 @@
 package domain
 
+import (
+	"errors"
+	"fmt"
+)
+
 type ErrorType string
 
 const (
-	ErrorTypeTeamExists  ErrorType = "TEAM_EXISTS"
-	ErrorTypePRExists    ErrorType = "PR_EXISTS"
-	ErrorTypePRMerged    ErrorType = "PR_MERGED"
-	ErrorTypeNotAssigned ErrorType = "NOT_ASSIGNED"
-	ErrorTypeNoCandidate ErrorType = "NO_CANDIDATE"
-	ErrorTypeNotFound    ErrorType = "NOT_FOUND"
-	ErrorTypeValidation  ErrorType = "VALIDATION_ERROR"
+	ErrorTypeTeamExists         ErrorType = "TEAM_EXISTS"
+	ErrorTypePRExists           ErrorType = "PR_EXISTS"
+	ErrorTypePRMerged           ErrorType = "PR_MERGED"
+	ErrorTypeNotAssigned        ErrorType = "NOT_ASSIGNED"
+	ErrorTypeNoCandidate        ErrorType = "NO_CANDIDATE"
+	ErrorTypeNotFound           ErrorType = "NOT_FOUND"
+	ErrorTypeValidation         ErrorType = "VALIDATION_ERROR"
+	ErrorTypeInvalidTransition  ErrorType = "INVALID_TRANSITION"
+	ErrorTypePRNotOpen          ErrorType = "PR_NOT_OPEN"
+	ErrorTypeNotEnoughApprovals ErrorType = "NOT_ENOUGH_APPROVALS"
+	ErrorTypeConflict           ErrorType = "CONFLICT"
+	ErrorTypeIdempotencyReused  ErrorType = "IDEMPOTENCY_KEY_REUSED"
+	ErrorTypeRequestInProgress  ErrorType = "REQUEST_IN_PROGRESS"
+	ErrorTypeUserInOtherTeam    ErrorType = "USER_IN_OTHER_TEAM"
+	ErrorTypeTeamHasPRs         ErrorType = "TEAM_HAS_PULL_REQUESTS"
+	ErrorTypeUnauthorized       ErrorType = "UNAUTHORIZED"
+	ErrorTypeForbidden          ErrorType = "FORBIDDEN"
 )
 
+// ErrDuplicateKey is returned by repositories when a create violates a unique
+// constraint, e.g. when a concurrent request inserted the same row first.
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// ErrNotFound is returned by repositories whose callers must tell a missing
+// row apart from a failed query, such as token lookups during authentication.
+var ErrNotFound = errors.New("not found")
+
+// ErrVersionConflict is returned by repositories when a pull request was
+// modified since it was read.
+var ErrVersionConflict = errors.New("version conflict")
+
 type DomainError struct {
 	Type    ErrorType
 	Message string
@@ -76,3 +103,77 @@ func NewValidationError(message string) *DomainError {
 		Message: message,
 	}
 }
+
+func NewInvalidTransitionError(from, to string) *DomainError {
+	return &DomainError{
+		Type:    ErrorTypeInvalidTransition,
+		Message: "cannot move pull request from " + from + " to " + to,
+	}
+}
+
+func NewPRNotOpenError() *DomainError {
+	return &DomainError{
+		Type:    ErrorTypePRNotOpen,
+		Message: "pull request is not open",
+	}
+}
+
+func NewNotEnoughApprovalsError(approvals, required int) *DomainError {
+	return &DomainError{
+		Type:    ErrorTypeNotEnoughApprovals,
+		Message: fmt.Sprintf("pull request has %d of %d required approvals", approvals, required),
+	}
+}
+
+func NewConflictError() *DomainError {
+	return &DomainError{
+		Type:    ErrorTypeConflict,
+		Message: "pull request was modified concurrently, retry the request",
+	}
+}
+
+func NewIdempotencyReusedError() *DomainError {
+	return &DomainError{
+		Type:    ErrorTypeIdempotencyReused,
+		Message: "idempotency key was already used with a different request",
+	}
+}
+
+func NewRequestInProgressError() *DomainError {
+	return &DomainError{
+		Type:    ErrorTypeRequestInProgress,
+		Message: "a request with this idempotency key is still in progress",
+	}
+}
+
+func NewUserInOtherTeamError(userID, teamName string) *DomainError {
+	message := "user " + userID + " already belongs to another team"
+	if teamName != "" {
+		message = "user " + userID + " already belongs to team " + teamName
+	}
+	return &DomainError{
+		Type:    ErrorTypeUserInOtherTeam,
+		Message: message,
+	}
+}
+
+func NewTeamHasPRsError() *DomainError {
+	return &DomainError{
+		Type:    ErrorTypeTeamHasPRs,
+		Message: "team members have pull requests or reviews, archive the team instead",
+	}
+}
+
+func NewUnauthorizedError() *DomainError {
+	return &DomainError{
+		Type:    ErrorTypeUnauthorized,
+		Message: "missing, invalid or revoked API token",
+	}
+}
+
+func NewForbiddenError(message string) *DomainError {
+	return &DomainError{
+		Type:    ErrorTypeForbidden,
+		Message: message,
+	}
+}
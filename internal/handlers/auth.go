@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+const bearerScheme = "Bearer "
+
+// Authenticate resolves the bearer token of every request except those to
+// publicPaths and stores the caller in the request context.
+func Authenticate(auth domain.AuthService, publicPaths ...string) echo.MiddlewareFunc {
+	public := make(map[string]bool, len(publicPaths))
+	for _, path := range publicPaths {
+		public[path] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if public[req.URL.Path] {
+				return next(c)
+			}
+
+			header := req.Header.Get(echo.HeaderAuthorization)
+			secret, ok := strings.CutPrefix(header, bearerScheme)
+			if !ok {
+				secret = ""
+			}
+
+			caller, err := auth.Authenticate(req.Context(), strings.TrimSpace(secret))
+			var domainErr *domain.DomainError
+			if errors.As(err, &domainErr) {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+				return respondDomainError(c, domainErr)
+			}
+			if err != nil {
+				return err
+			}
+
+			c.SetRequest(req.WithContext(domain.WithCaller(req.Context(), *caller)))
+			return next(c)
+		}
+	}
+}
+
+// RequireRole rejects callers whose token has none of the given roles. Admin
+// tokens are accepted everywhere.
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			caller, ok := domain.CallerFromContext(c.Request().Context())
+			if ok && caller.Role == domain.RoleAdmin {
+				return next(c)
+			}
+			for _, role := range roles {
+				if ok && caller.Role == role {
+					return next(c)
+				}
+			}
+
+			return respondDomainError(c, domain.NewForbiddenError(
+				"this operation requires one of the roles "+strings.Join(roles, ", ")))
+		}
+	}
+}
@@ -3,14 +3,55 @@ package dto
 import "github.com/nikitaenmi/AvitoTest/internal/domain"
 
 type CreateTeamRequest struct {
-	TeamName string        `json:"team_name"`
-	Members  []UserRequest `json:"members"`
+	TeamName          string        `json:"team_name"`
+	RequiredReviewers int           `json:"required_reviewers"`
+	RequiredApprovals int           `json:"required_approvals"`
+	FallbackTeams     []string      `json:"fallback_teams"`
+	Members           []UserRequest `json:"members"`
+}
+
+type SetFallbackTeamsRequest struct {
+	TeamName      string   `json:"team_name"`
+	FallbackTeams []string `json:"fallback_teams"`
 }
 
 type UserRequest struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	IsActive bool   `json:"is_active"`
+	Role     string `json:"role"`
+}
+
+type DeactivateUsersRequest struct {
+	TeamName string   `json:"team_name"`
+	UserIDs  []string `json:"user_ids"`
+}
+
+type AddTeamMembersRequest struct {
+	TeamName string        `json:"team_name"`
+	Members  []UserRequest `json:"members"`
+}
+
+type RemoveTeamMemberRequest struct {
+	TeamName string `json:"team_name"`
+	UserID   string `json:"user_id"`
+}
+
+type MoveUserRequest struct {
+	UserID   string `json:"user_id"`
+	TeamName string `json:"team_name"`
+	Username string `json:"username"`
+}
+
+type DeleteTeamQuery struct {
+	TeamName     string `query:"team_name"`
+	Hard         bool   `query:"hard"`
+	CloseOpenPRs bool   `query:"close_open_prs"`
+}
+
+type SetUserRoleRequest struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
 }
 
 type SetUserActiveRequest struct {
@@ -22,31 +63,91 @@ type CreatePRRequest struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
+	Draft           bool   `json:"draft"`
 }
 
 type MergePRRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 }
 
+type SubmitReviewRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+	State         string `json:"state"`
+	Comment       string `json:"comment"`
+}
+
+type PRStatusChangeRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+}
+
+type TopUpReviewersRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+}
+
 type ReassignReviewerRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 	OldUserID     string `json:"old_user_id"`
+	Reason        string `json:"reason"`
 }
 
 func (r CreateTeamRequest) ToDomain() domain.Team {
-	members := make([]domain.User, len(r.Members))
-	for i, member := range r.Members {
+	return domain.Team{
+		TeamName:          r.TeamName,
+		RequiredReviewers: r.RequiredReviewers,
+		RequiredApprovals: r.RequiredApprovals,
+		FallbackTeams:     r.FallbackTeams,
+		Members:           membersToDomain(r.Members),
+	}
+}
+
+func (r AddTeamMembersRequest) ToTeamFilter() domain.TeamFilter {
+	return domain.TeamFilter{TeamName: &r.TeamName}
+}
+
+func (r AddTeamMembersRequest) ToDomain() []domain.User {
+	return membersToDomain(r.Members)
+}
+
+func (r RemoveTeamMemberRequest) ToTeamFilter() domain.TeamFilter {
+	return domain.TeamFilter{TeamName: &r.TeamName}
+}
+
+func (r MoveUserRequest) ToUserFilter() domain.UserFilter {
+	return domain.UserFilter{UserID: &r.UserID}
+}
+
+func membersToDomain(requests []UserRequest) []domain.User {
+	members := make([]domain.User, len(requests))
+	for i, member := range requests {
 		members[i] = domain.User{
 			UserID:   member.UserID,
 			Username: member.Username,
 			IsActive: member.IsActive,
+			Role:     member.Role,
 		}
 	}
+	return members
+}
 
-	return domain.Team{
-		TeamName: r.TeamName,
-		Members:  members,
-	}
+func (r SetFallbackTeamsRequest) ToTeamFilter() domain.TeamFilter {
+	return domain.TeamFilter{TeamName: &r.TeamName}
+}
+
+func (r DeactivateUsersRequest) ToTeamFilter() domain.TeamFilter {
+	return domain.TeamFilter{TeamName: &r.TeamName}
+}
+
+func (q DeleteTeamQuery) ToTeamFilter() domain.TeamFilter {
+	return domain.TeamFilter{TeamName: &q.TeamName}
+}
+
+func (q DeleteTeamQuery) ToDeleteOptions() domain.TeamDeleteOptions {
+	return domain.TeamDeleteOptions{Hard: q.Hard, CloseOpenPRs: q.CloseOpenPRs}
+}
+
+func (r SetUserRoleRequest) ToUserFilter() domain.UserFilter {
+	return domain.UserFilter{UserID: &r.UserID}
 }
 
 func (r SetUserActiveRequest) ToUserFilter() domain.UserFilter {
@@ -54,10 +155,16 @@ func (r SetUserActiveRequest) ToUserFilter() domain.UserFilter {
 }
 
 func (r CreatePRRequest) ToDomain() domain.PullRequest {
+	status := domain.PRStatusOpen
+	if r.Draft {
+		status = domain.PRStatusDraft
+	}
+
 	return domain.PullRequest{
 		PullRequestID:   r.PullRequestID,
 		PullRequestName: r.PullRequestName,
 		AuthorID:        r.AuthorID,
+		Status:          status,
 	}
 }
 
@@ -65,12 +172,29 @@ func (r MergePRRequest) ToPRFilter() domain.PRFilter {
 	return domain.PRFilter{PullRequestID: &r.PullRequestID}
 }
 
+func (r SubmitReviewRequest) ToDomain() domain.Review {
+	return domain.Review{
+		PullRequestID: r.PullRequestID,
+		ReviewerID:    r.ReviewerID,
+		State:         r.State,
+		Comment:       r.Comment,
+	}
+}
+
+func (r PRStatusChangeRequest) ToPRFilter() domain.PRFilter {
+	return domain.PRFilter{PullRequestID: &r.PullRequestID}
+}
+
+func (r TopUpReviewersRequest) ToPRFilter() domain.PRFilter {
+	return domain.PRFilter{PullRequestID: &r.PullRequestID}
+}
+
 func (r ReassignReviewerRequest) ToPRFilter() domain.PRFilter {
 	return domain.PRFilter{PullRequestID: &r.PullRequestID}
 }
 
-func TeamFilterFromQuery(teamName string) domain.TeamFilter {
-	return domain.TeamFilter{TeamName: &teamName}
+func TeamFilterFromQuery(teamName string, includeArchived bool) domain.TeamFilter {
+	return domain.TeamFilter{TeamName: &teamName, IncludeArchived: includeArchived}
 }
 
 func UserFilterFromQuery(userID string) domain.UserFilter {
@@ -0,0 +1,122 @@
+package dto
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+type ListPRsQuery struct {
+	AuthorID    string `query:"author_id"`
+	Status      string `query:"status"`
+	ReviewerID  string `query:"reviewer_id"`
+	TeamName    string `query:"team_name"`
+	CreatedFrom string `query:"created_from"`
+	CreatedTo   string `query:"created_to"`
+	MergedFrom  string `query:"merged_from"`
+	MergedTo    string `query:"merged_to"`
+	Cursor      string `query:"cursor"`
+	Limit       int    `query:"limit"`
+}
+
+func (q ListPRsQuery) ToPRFilter() (domain.PRFilter, error) {
+	filter := domain.PRFilter{
+		AuthorID:   optionalString(q.AuthorID),
+		Status:     optionalString(q.Status),
+		ReviewerID: optionalString(q.ReviewerID),
+		TeamName:   optionalString(q.TeamName),
+		Limit:      q.Limit,
+	}
+
+	var err error
+	if filter.CreatedFrom, err = parseTimeParam("created_from", q.CreatedFrom); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = parseTimeParam("created_to", q.CreatedTo); err != nil {
+		return filter, err
+	}
+	if filter.MergedFrom, err = parseTimeParam("merged_from", q.MergedFrom); err != nil {
+		return filter, err
+	}
+	if filter.MergedTo, err = parseTimeParam("merged_to", q.MergedTo); err != nil {
+		return filter, err
+	}
+	if filter.After, err = DecodePRCursor(q.Cursor); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+type StatsQuery struct {
+	TeamName string `query:"team_name"`
+	From     string `query:"from"`
+	To       string `query:"to"`
+	Limit    int    `query:"limit"`
+}
+
+func (q StatsQuery) ToStatsFilter() (domain.StatsFilter, error) {
+	filter := domain.StatsFilter{TeamName: optionalString(q.TeamName), Limit: q.Limit}
+
+	var err error
+	if filter.From, err = parseTimeParam("from", q.From); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseTimeParam("to", q.To); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+func EncodePRCursor(cursor *domain.PRCursor) string {
+	if cursor == nil {
+		return ""
+	}
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.PullRequestID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodePRCursor(value string) (*domain.PRCursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, domain.NewValidationError("invalid cursor")
+	}
+
+	createdAt, prID, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, domain.NewValidationError("invalid cursor")
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, domain.NewValidationError("invalid cursor")
+	}
+
+	return &domain.PRCursor{CreatedAt: parsed, PullRequestID: prID}, nil
+}
+
+func parseTimeParam(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, domain.NewValidationError(name + " must be an RFC 3339 timestamp")
+	}
+	return &parsed, nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
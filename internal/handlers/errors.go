@@ -15,6 +15,30 @@ type ErrorResponse struct {
 	} `json:"error"`
 }
 
+const handledErrorKey = "handled_error"
+
+// HandleErrors writes the response for an error returned further down the
+// chain and stops it there, so the middlewares registered before it only read
+// the final status from c.Response().Status. It must wrap everything that can
+// fail, including the recover middleware.
+func HandleErrors() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := next(c); err != nil {
+				c.Set(handledErrorKey, err)
+				c.Error(err)
+			}
+			return nil
+		}
+	}
+}
+
+// handledError returns the error HandleErrors answered the request with.
+func handledError(c echo.Context) error {
+	err, _ := c.Get(handledErrorKey).(error)
+	return err
+}
+
 func (h *Handlers) handleError(c echo.Context, err error) error {
 	var domainErr *domain.DomainError
 	if ok := errors.As(err, &domainErr); ok {
@@ -37,15 +61,28 @@ func (h *Handlers) handleError(c echo.Context, err error) error {
 }
 
 func (h *Handlers) handleDomainError(c echo.Context, domainErr *domain.DomainError) error {
+	return respondDomainError(c, domainErr)
+}
+
+func respondDomainError(c echo.Context, domainErr *domain.DomainError) error {
 	var statusCode int
 
 	switch domainErr.Type {
 	case domain.ErrorTypeTeamExists, domain.ErrorTypeValidation:
 		statusCode = http.StatusBadRequest
-	case domain.ErrorTypePRExists, domain.ErrorTypePRMerged, domain.ErrorTypeNotAssigned, domain.ErrorTypeNoCandidate:
+	case domain.ErrorTypePRExists, domain.ErrorTypePRMerged, domain.ErrorTypeNotAssigned, domain.ErrorTypeNoCandidate,
+		domain.ErrorTypeInvalidTransition, domain.ErrorTypePRNotOpen, domain.ErrorTypeNotEnoughApprovals,
+		domain.ErrorTypeConflict, domain.ErrorTypeRequestInProgress, domain.ErrorTypeUserInOtherTeam,
+		domain.ErrorTypeTeamHasPRs:
 		statusCode = http.StatusConflict
+	case domain.ErrorTypeIdempotencyReused:
+		statusCode = http.StatusUnprocessableEntity
 	case domain.ErrorTypeNotFound:
 		statusCode = http.StatusNotFound
+	case domain.ErrorTypeUnauthorized:
+		statusCode = http.StatusUnauthorized
+	case domain.ErrorTypeForbidden:
+		statusCode = http.StatusForbidden
 	default:
 		statusCode = http.StatusBadRequest
 	}
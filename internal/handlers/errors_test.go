@@ -0,0 +1,50 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/nikitaenmi/AvitoTest/internal/handlers"
+	"github.com/stretchr/testify/assert"
+)
+
+type statusRecorder struct {
+	statuses []int
+}
+
+func (r *statusRecorder) ObserveHTTPRequest(_, _ string, status int, _ time.Duration) {
+	r.statuses = append(r.statuses, status)
+}
+
+func TestMiddlewaresObserveHandledErrorStatus(t *testing.T) {
+	observer := &statusRecorder{}
+	handled := 0
+
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		handled++
+		e.DefaultHTTPErrorHandler(err, c)
+	}
+	e.Use(handlers.Instrument(observer))
+	e.Use(handlers.AccessLog())
+	e.Use(handlers.HandleErrors())
+	e.Use(middleware.Recover())
+	e.GET("/teapot", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusTeapot)
+	})
+	e.GET("/panic", func(c echo.Context) error {
+		panic("boom")
+	})
+
+	for _, path := range []string{"/teapot", "/panic", "/missing"} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, []int{http.StatusTeapot, http.StatusInternalServerError, http.StatusNotFound}, observer.statuses)
+	assert.Equal(t, 3, handled)
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nikitaenmi/AvitoTest/internal/health"
+)
+
+// Livez reports that the process is up and serving HTTP; it checks nothing
+// else, so a failing dependency never gets the process restarted.
+func Livez(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"status": health.StatusOK})
+}
+
+// Readyz reports whether the instance can take traffic, with the result of
+// every check, and answers 503 when any of them fails.
+func Readyz(checker *health.Checker) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		report := checker.Ready(c.Request().Context())
+		status := http.StatusOK
+		if !report.OK() {
+			status = http.StatusServiceUnavailable
+		}
+		return c.JSON(status, report)
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+	"github.com/nikitaenmi/AvitoTest/internal/logging"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// Idempotency replays the stored response for POST requests that repeat an
+// Idempotency-Key header, so clients can safely retry after a timeout.
+// Responses with a 5xx status are not stored and the key is released.
+func Idempotency(store domain.IdempotencyRepository, ttl time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			key := req.Header.Get(IdempotencyKeyHeader)
+			if req.Method != http.MethodPost || key == "" {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return respondDomainError(c, domain.NewValidationError("idempotency key is too long"))
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			// The response must be stored even if the client gave up waiting.
+			ctx := context.WithoutCancel(req.Context())
+			hash := requestHash(req, body)
+			now := time.Now()
+			record, reserved, err := store.Reserve(ctx, domain.IdempotencyRecord{
+				Key:         key,
+				RequestHash: hash,
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(ttl),
+			})
+			if err != nil {
+				return err
+			}
+
+			if !reserved {
+				if record.RequestHash != hash {
+					return respondDomainError(c, domain.NewIdempotencyReusedError())
+				}
+				if record.StatusCode == 0 {
+					return respondDomainError(c, domain.NewRequestInProgressError())
+				}
+				c.Response().Header().Set(IdempotentReplayedHeader, "true")
+				return c.Blob(record.StatusCode, record.ContentType, record.ResponseBody)
+			}
+
+			// Recover runs outside this middleware, so a panicking handler
+			// would otherwise leave the key reserved until it expires.
+			defer func() {
+				if r := recover(); r != nil {
+					releaseIdempotencyKey(ctx, store, key)
+					panic(r)
+				}
+			}()
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			res := c.Response()
+			if !res.Committed || res.Status >= http.StatusInternalServerError {
+				releaseIdempotencyKey(ctx, store, key)
+				return nil
+			}
+
+			contentType := res.Header().Get(echo.HeaderContentType)
+			if err := store.Complete(ctx, key, res.Status, contentType, recorder.body.Bytes()); err != nil {
+				logging.FromContext(ctx).Error("failed to store response for idempotency key",
+					slog.String("key", key), slog.Any("error", err))
+			}
+			return nil
+		}
+	}
+}
+
+func releaseIdempotencyKey(ctx context.Context, store domain.IdempotencyRepository, key string) {
+	if err := store.Release(ctx, key); err != nil {
+		logging.FromContext(ctx).Error("failed to release idempotency key",
+			slog.String("key", key), slog.Any("error", err))
+	}
+}
+
+// requestHash covers the caller too, so a key reused by another client is
+// rejected instead of replaying someone else's response.
+func requestHash(req *http.Request, body []byte) string {
+	var callerID int64
+	if caller, ok := domain.CallerFromContext(req.Context()); ok {
+		callerID = caller.TokenID
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d %s %s\n", callerID, req.Method, req.URL.Path)
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
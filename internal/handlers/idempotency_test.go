@@ -0,0 +1,52 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/nikitaenmi/AvitoTest/internal/handlers"
+	"github.com/nikitaenmi/AvitoTest/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyReleasesKeyWhenHandlerPanics(t *testing.T) {
+	repos := memory.NewRepositories(memory.NewStore())
+
+	calls := 0
+	e := echo.New()
+	e.Use(middleware.Recover())
+	e.Use(handlers.Idempotency(repos.Idempotency, time.Hour))
+	e.POST("/pullRequest/create", func(c echo.Context) error {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		return c.JSON(http.StatusCreated, map[string]string{"status": "created"})
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", strings.NewReader(`{"pull_request_id":"pr-1"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(handlers.IdempotencyKeyHeader, "key-1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusInternalServerError, send().Code)
+
+	rec := send()
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, 2, calls)
+
+	rec = send()
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get(handlers.IdempotentReplayedHeader))
+	assert.Equal(t, 2, calls)
+}
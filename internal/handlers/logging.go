@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nikitaenmi/AvitoTest/internal/logging"
+	"github.com/nikitaenmi/AvitoTest/internal/tracing"
+)
+
+const maxRequestIDLength = 128
+
+// RequestID takes the request ID from the X-Request-ID header or generates
+// one, echoes it in the response, and puts a logger tagged with it (and with
+// the trace ID when the request is traced) into the request context.
+func RequestID(logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			requestID := req.Header.Get(echo.HeaderXRequestID)
+			if !validRequestID(requestID) {
+				requestID = newRequestID()
+			}
+			c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+
+			scoped := logger.With(slog.String("request_id", requestID))
+			if sc := tracing.SpanFromContext(req.Context()).SpanContext(); sc.IsValid() {
+				scoped = scoped.With(slog.String("trace_id", sc.TraceID.String()))
+			}
+			c.SetRequest(req.WithContext(logging.WithLogger(req.Context(), scoped)))
+			return next(c)
+		}
+	}
+}
+
+// validRequestID accepts client IDs of printable ASCII only, so that they can
+// be logged and echoed back safely.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// AccessLog writes one line per request with the request-scoped logger.
+// Server errors are logged at error level.
+func AccessLog() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			req := c.Request()
+			res := c.Response()
+			level := slog.LevelInfo
+			if res.Status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("path", req.URL.Path),
+				slog.String("route", c.Path()),
+				slog.Int("status", res.Status),
+				slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.Int64("bytes_out", res.Size),
+				slog.String("remote_ip", c.RealIP()),
+			}
+			if handled := handledError(c); handled != nil {
+				attrs = append(attrs, slog.Any("error", handled))
+			}
+			logging.FromContext(req.Context()).LogAttrs(req.Context(), level, "request", attrs...)
+			return err
+		}
+	}
+}
+
+// RecoverLogger logs panics recovered by echo's Recover middleware.
+func RecoverLogger(c echo.Context, err error, stack []byte) error {
+	logging.FromContext(c.Request().Context()).Error("panic recovered",
+		slog.Any("error", err), slog.String("stack", string(stack)))
+	return err
+}
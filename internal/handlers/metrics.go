@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type RequestObserver interface {
+	ObserveHTTPRequest(method, route string, status int, duration time.Duration)
+}
+
+// Instrument reports every request with its route pattern rather than the raw
+// path, so that path parameters do not multiply the series.
+func Instrument(observer RequestObserver) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			observer.ObserveHTTPRequest(c.Request().Method, route, c.Response().Status, time.Since(start))
+			return err
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
 	"github.com/nikitaenmi/AvitoTest/internal/handlers/dto"
 )
 
@@ -36,6 +38,50 @@ func (h *Handlers) MergePR(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{"message": "pull request merged"})
 }
 
+func (h *Handlers) ClosePR(c echo.Context) error {
+	return h.changePRStatus(c, h.service.ClosePR)
+}
+
+func (h *Handlers) ReopenPR(c echo.Context) error {
+	return h.changePRStatus(c, h.service.ReopenPR)
+}
+
+func (h *Handlers) MarkPRReady(c echo.Context) error {
+	return h.changePRStatus(c, h.service.MarkPRReady)
+}
+
+func (h *Handlers) changePRStatus(
+	c echo.Context, change func(context.Context, domain.PRFilter) (*domain.PullRequest, error),
+) error {
+	var req dto.PRStatusChangeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON format"})
+	}
+
+	ctx := c.Request().Context()
+	pr, err := change(ctx, req.ToPRFilter())
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+func (h *Handlers) TopUpReviewers(c echo.Context) error {
+	var req dto.TopUpReviewersRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON format"})
+	}
+
+	ctx := c.Request().Context()
+	pr, err := h.service.TopUpReviewers(ctx, req.ToPRFilter())
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
 func (h *Handlers) ReassignReviewer(c echo.Context) error {
 	var req dto.ReassignReviewerRequest
 	if err := c.Bind(&req); err != nil {
@@ -44,7 +90,7 @@ func (h *Handlers) ReassignReviewer(c echo.Context) error {
 
 	ctx := c.Request().Context()
 
-	newReviewerID, err := h.service.ReassignReviewer(ctx, req.ToPRFilter(), req.OldUserID)
+	newReviewerID, err := h.service.ReassignReviewer(ctx, req.ToPRFilter(), req.OldUserID, req.Reason)
 	if err != nil {
 		return h.handleError(c, err)
 	}
@@ -59,3 +105,60 @@ func (h *Handlers) ReassignReviewer(c echo.Context) error {
 		"replaced_by": newReviewerID,
 	})
 }
+
+func (h *Handlers) GetPR(c echo.Context) error {
+	prID := c.QueryParam("pull_request_id")
+	if prID == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "pull_request_id is required"})
+	}
+
+	ctx := c.Request().Context()
+	pr, err := h.service.GetPR(ctx, dto.PRFilterFromQuery(prID))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+func (h *Handlers) ListPRs(c echo.Context) error {
+	var query dto.ListPRsQuery
+	if err := c.Bind(&query); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid query parameters"})
+	}
+
+	filter, err := query.ToPRFilter()
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	ctx := c.Request().Context()
+	prs, next, err := h.service.ListPRs(ctx, filter)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	response := map[string]interface{}{"pull_requests": prs}
+	if next != nil {
+		response["next_cursor"] = dto.EncodePRCursor(next)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *Handlers) GetAssignmentHistory(c echo.Context) error {
+	prID := c.QueryParam("pull_request_id")
+	if prID == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "pull_request_id is required"})
+	}
+
+	ctx := c.Request().Context()
+	history, err := h.service.GetAssignmentHistory(ctx, dto.PRFilterFromQuery(prID))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"pull_request_id": prID,
+		"history":         history,
+	})
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nikitaenmi/AvitoTest/internal/handlers/dto"
+)
+
+func (h *Handlers) SubmitReview(c echo.Context) error {
+	var req dto.SubmitReviewRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON format"})
+	}
+
+	ctx := c.Request().Context()
+	pr, err := h.service.SubmitReview(ctx, req.ToDomain())
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"pr": pr})
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nikitaenmi/AvitoTest/internal/handlers/dto"
+)
+
+func (h *Handlers) GetStats(c echo.Context) error {
+	var query dto.StatsQuery
+	if err := c.Bind(&query); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid query parameters"})
+	}
+
+	filter, err := query.ToStatsFilter()
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	ctx := c.Request().Context()
+	stats, err := h.service.GetStats(ctx, filter)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
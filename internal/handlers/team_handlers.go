@@ -29,10 +29,90 @@ func (h *Handlers) GetTeam(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
-	team, err := h.service.GetTeam(ctx, dto.TeamFilterFromQuery(teamName))
+	includeArchived := c.QueryParam("include_archived") == "true"
+	team, err := h.service.GetTeam(ctx, dto.TeamFilterFromQuery(teamName, includeArchived))
 	if err != nil {
 		return h.handleError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{"team": team})
 }
+
+func (h *Handlers) SetFallbackTeams(c echo.Context) error {
+	var req dto.SetFallbackTeamsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON format"})
+	}
+
+	ctx := c.Request().Context()
+	team, err := h.service.SetFallbackTeams(ctx, req.ToTeamFilter(), req.FallbackTeams)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"team": team})
+}
+
+func (h *Handlers) DeactivateTeamUsers(c echo.Context) error {
+	var req dto.DeactivateUsersRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON format"})
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.DeactivateTeamUsers(ctx, req.ToTeamFilter(), req.UserIDs)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+func (h *Handlers) AddTeamMembers(c echo.Context) error {
+	var req dto.AddTeamMembersRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON format"})
+	}
+
+	ctx := c.Request().Context()
+	team, err := h.service.AddTeamMembers(ctx, req.ToTeamFilter(), req.ToDomain())
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"team": team})
+}
+
+func (h *Handlers) RemoveTeamMember(c echo.Context) error {
+	var req dto.RemoveTeamMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON format"})
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.RemoveTeamMember(ctx, req.ToTeamFilter(), req.UserID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":      "user removed from team",
+		"reassigned":   report.Reassigned,
+		"no_candidate": report.NoCandidate,
+	})
+}
+
+func (h *Handlers) DeleteTeam(c echo.Context) error {
+	var req dto.DeleteTeamQuery
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid query parameters"})
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.DeleteTeam(ctx, req.ToTeamFilter(), req.ToDeleteOptions())
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
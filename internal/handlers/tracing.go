@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nikitaenmi/AvitoTest/internal/tracing"
+)
+
+// Trace starts a server span for every request, continuing the trace from an
+// incoming traceparent header, and returns the span in a traceparent response
+// header so clients can look the request up.
+func Trace(tracer *tracing.Tracer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !tracer.Enabled() {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := req.Context()
+			if parent, ok := tracing.ParseTraceparent(req.Header.Get(tracing.TraceparentHeader)); ok {
+				ctx = tracing.ContextWithRemoteParent(ctx, parent)
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			ctx, span := tracer.Start(ctx, req.Method+" "+route, tracing.SpanKindServer)
+			defer span.End()
+
+			c.SetRequest(req.WithContext(ctx))
+			c.Response().Header().Set(tracing.TraceparentHeader, tracing.FormatTraceparent(span.SpanContext()))
+
+			err := next(c)
+
+			status := c.Response().Status
+			span.SetAttributes(
+				tracing.String("http.request.method", req.Method),
+				tracing.String("http.route", route),
+				tracing.String("url.path", req.URL.Path),
+				tracing.Int("http.response.status_code", status),
+			)
+			if status >= http.StatusInternalServerError {
+				span.RecordError(echo.NewHTTPError(status))
+			}
+			return err
+		}
+	}
+}
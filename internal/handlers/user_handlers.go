@@ -14,11 +14,50 @@ func (h *Handlers) SetUserActive(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
-	if err := h.service.SetUserActive(ctx, req.ToUserFilter(), req.IsActive); err != nil {
+	report, err := h.service.SetUserActive(ctx, req.ToUserFilter(), req.IsActive)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":      "user activity updated",
+		"reassigned":   report.Reassigned,
+		"no_candidate": report.NoCandidate,
+	})
+}
+
+func (h *Handlers) SetUserRole(c echo.Context) error {
+	var req dto.SetUserRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON format"})
+	}
+
+	ctx := c.Request().Context()
+	user, err := h.service.SetUserRole(ctx, req.ToUserFilter(), req.Role)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"user": user})
+}
+
+func (h *Handlers) MoveUserToTeam(c echo.Context) error {
+	var req dto.MoveUserRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON format"})
+	}
+
+	ctx := c.Request().Context()
+	user, report, err := h.service.MoveUserToTeam(ctx, req.ToUserFilter(), req.TeamName, req.Username)
+	if err != nil {
 		return h.handleError(c, err)
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"message": "user activity updated"})
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"user":         user,
+		"reassigned":   report.Reassigned,
+		"no_candidate": report.NoCandidate,
+	})
 }
 
 func (h *Handlers) GetUserReviewPRs(c echo.Context) error {
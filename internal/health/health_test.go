@@ -0,0 +1,75 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func passing(details map[string]any) health.Check {
+	return func(context.Context) (map[string]any, error) { return details, nil }
+}
+
+func TestReadyAggregatesChecks(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Add("database", passing(nil))
+	checker.Add("migrations", passing(map[string]any{"pending": []int{}}))
+
+	report := checker.Ready(context.Background())
+	assert.True(t, report.OK())
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+	assert.Equal(t, []int{}, report.Checks["migrations"].Details["pending"])
+
+	checker.Add("connection_pool", func(context.Context) (map[string]any, error) {
+		return map[string]any{"saturation": 1.0}, errors.New("connection pool is 100% saturated")
+	})
+
+	report = checker.Ready(context.Background())
+	assert.False(t, report.OK())
+	assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+	pool := report.Checks["connection_pool"]
+	assert.Equal(t, health.StatusFail, pool.Status)
+	assert.Equal(t, "connection pool is 100% saturated", pool.Error)
+	assert.Equal(t, 1.0, pool.Details["saturation"])
+}
+
+func TestReadyTimesOutSlowChecks(t *testing.T) {
+	checker := health.NewChecker(20 * time.Millisecond)
+	checker.Add("database", func(ctx context.Context) (map[string]any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	checker.Add("migrations", passing(nil))
+
+	start := time.Now()
+	report := checker.Ready(context.Background())
+	assert.Less(t, time.Since(start), time.Second)
+
+	assert.False(t, report.OK())
+	assert.Equal(t, health.StatusFail, report.Checks["database"].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["database"].Error)
+	assert.Equal(t, health.StatusOK, report.Checks["migrations"].Status)
+}
+
+func TestReadyFailsWhileDraining(t *testing.T) {
+	called := false
+	checker := health.NewChecker(time.Second)
+	checker.Add("database", func(context.Context) (map[string]any, error) {
+		called = true
+		return nil, nil
+	})
+
+	checker.Drain()
+	report := checker.Ready(context.Background())
+
+	assert.True(t, checker.Draining())
+	assert.False(t, report.OK())
+	assert.Equal(t, health.StatusFail, report.Checks["shutdown"].Status)
+	assert.False(t, called)
+}
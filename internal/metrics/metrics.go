@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var dbBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}
+
+// Metrics holds the service metrics. It implements domain.EventRecorder and
+// the query observer of the instrumented repositories.
+type Metrics struct {
+	registry *Registry
+
+	httpRequests  *CounterVec
+	httpDuration  *HistogramVec
+	queryDuration *HistogramVec
+
+	prsCreated      *CounterVec
+	prsUnderstaffed *CounterVec
+	prsMerged       *CounterVec
+	reassignments   *CounterVec
+	noCandidate     *CounterVec
+}
+
+func New() *Metrics {
+	r := NewRegistry()
+	return &Metrics{
+		registry: r,
+		httpRequests: r.NewCounterVec("http_requests_total",
+			"HTTP requests by method, route and status code.", "method", "route", "status"),
+		httpDuration: r.NewHistogramVec("http_request_duration_seconds",
+			"HTTP request latency by method, route and status code.", DefBuckets, "method", "route", "status"),
+		queryDuration: r.NewHistogramVec("db_query_duration_seconds",
+			"Storage call latency by repository and method.", dbBuckets, "repository", "method"),
+		prsCreated: r.NewCounterVec("pull_requests_created_total",
+			"Pull requests created."),
+		prsUnderstaffed: r.NewCounterVec("pull_requests_understaffed_total",
+			"Pull requests created or reopened with fewer reviewers than their team requires."),
+		prsMerged: r.NewCounterVec("pull_requests_merged_total",
+			"Pull requests merged."),
+		reassignments: r.NewCounterVec("reviewer_reassignments_total",
+			"Reviewers replaced on open pull requests, manually or after leaving."),
+		noCandidate: r.NewCounterVec("reviewer_no_candidate_errors_total",
+			"Reviewers left without a replacement (NO_CANDIDATE)."),
+	}
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return m.registry.Handler()
+}
+
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.httpRequests.Inc(method, route, code)
+	m.httpDuration.Observe(duration.Seconds(), method, route, code)
+}
+
+func (m *Metrics) StartQuery(ctx context.Context, repository, method string) (context.Context, func(error)) {
+	start := time.Now()
+	return ctx, func(error) {
+		m.queryDuration.Observe(time.Since(start).Seconds(), repository, method)
+	}
+}
+
+func (m *Metrics) PRCreated(assignedReviewers, requiredReviewers int) {
+	m.prsCreated.Inc()
+	if assignedReviewers < requiredReviewers {
+		m.prsUnderstaffed.Inc()
+	}
+}
+
+func (m *Metrics) PROpened(assignedReviewers, requiredReviewers int) {
+	if assignedReviewers < requiredReviewers {
+		m.prsUnderstaffed.Inc()
+	}
+}
+
+func (m *Metrics) PRMerged() {
+	m.prsMerged.Inc()
+}
+
+func (m *Metrics) ReviewersReassigned(count int) {
+	m.reassignments.Add(float64(count))
+}
+
+func (m *Metrics) NoCandidate() {
+	m.noCandidate.Inc()
+}
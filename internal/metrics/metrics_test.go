@@ -0,0 +1,81 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, handler http.Handler) string {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	return rec.Body.String()
+}
+
+func TestRegistryExposition(t *testing.T) {
+	r := metrics.NewRegistry()
+	counter := r.NewCounterVec("jobs_total", "Jobs by queue.\nSecond line.", "queue")
+	histogram := r.NewHistogramVec("job_seconds", "Job latency.", []float64{1, 0.1}, "queue")
+
+	counter.Inc(`b"q`)
+	counter.Add(2, "a")
+	counter.Add(-1, "a")
+	histogram.Observe(0.05, "a")
+	histogram.Observe(0.5, "a")
+	histogram.Observe(3, "a")
+
+	expected := strings.Join([]string{
+		`# HELP jobs_total Jobs by queue.\nSecond line.`,
+		`# TYPE jobs_total counter`,
+		`jobs_total{queue="a"} 2`,
+		`jobs_total{queue="b\"q"} 1`,
+		`# HELP job_seconds Job latency.`,
+		`# TYPE job_seconds histogram`,
+		`job_seconds_bucket{queue="a",le="0.1"} 1`,
+		`job_seconds_bucket{queue="a",le="1"} 2`,
+		`job_seconds_bucket{queue="a",le="+Inf"} 3`,
+		`job_seconds_sum{queue="a"} 3.55`,
+		`job_seconds_count{queue="a"} 3`,
+		``,
+	}, "\n")
+	assert.Equal(t, expected, scrape(t, r.Handler()))
+	assert.Panics(t, func() { counter.Inc("a", "b") })
+}
+
+func TestMetrics(t *testing.T) {
+	m := metrics.New()
+	m.ObserveHTTPRequest(http.MethodPost, "/pullRequest/create", http.StatusCreated, 20*time.Millisecond)
+	_, done := m.StartQuery(context.Background(), "pull_requests", "Create")
+	done(errors.New("boom"))
+	m.PRCreated(2, 2)
+	m.PRCreated(1, 2)
+	m.PROpened(0, 2)
+	m.PROpened(2, 2)
+	m.PRMerged()
+	m.ReviewersReassigned(3)
+	m.NoCandidate()
+
+	text := scrape(t, m.Handler())
+	for _, line := range []string{
+		`http_requests_total{method="POST",route="/pullRequest/create",status="201"} 1`,
+		`http_request_duration_seconds_bucket{method="POST",route="/pullRequest/create",status="201",le="0.025"} 1`,
+		`db_query_duration_seconds_count{repository="pull_requests",method="Create"} 1`,
+		"pull_requests_created_total 2",
+		"pull_requests_understaffed_total 2",
+		"pull_requests_merged_total 1",
+		"reviewer_reassignments_total 3",
+		"reviewer_no_candidate_errors_total 1",
+	} {
+		assert.Contains(t, text, line+"\n")
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nikitaenmi/AvitoTest/internal/database/models"
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+	"gorm.io/gorm"
+)
+
+type AssignmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAssignmentRepository(db *gorm.DB) *AssignmentRepository {
+	return &AssignmentRepository{db: db}
+}
+
+func (r *AssignmentRepository) Create(ctx context.Context, assignments []domain.ReviewerAssignment) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	assignmentModels := make([]models.ReviewerAssignment, len(assignments))
+	for i, assignment := range assignments {
+		assignmentModels[i] = models.ReviewerAssignmentFromDomain(assignment)
+	}
+
+	if err := r.db.WithContext(ctx).CreateInBatches(&assignmentModels, 500).Error; err != nil {
+		return fmt.Errorf("failed to record reviewer assignments: %w", err)
+	}
+	return nil
+}
+
+func (r *AssignmentRepository) FindByPR(ctx context.Context, prID string) ([]domain.ReviewerAssignment, error) {
+	var assignmentModels []models.ReviewerAssignment
+	err := r.db.WithContext(ctx).
+		Where("pull_request_id = ?", prID).
+		Order("created_at").
+		Order("id").
+		Find(&assignmentModels).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reviewer assignments: %w", err)
+	}
+
+	return models.ReviewerAssignmentsToDomain(assignmentModels), nil
+}
+
+// FindLatestAssigned returns the most recent ASSIGNED entry for every reviewer
+// of the given pull requests.
+func (r *AssignmentRepository) FindLatestAssigned(
+	ctx context.Context, prIDs []string,
+) ([]domain.ReviewerAssignment, error) {
+	if len(prIDs) == 0 {
+		return nil, nil
+	}
+
+	var assignmentModels []models.ReviewerAssignment
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (pull_request_id, user_id) *
+			FROM reviewer_assignments
+			WHERE pull_request_id IN ? AND action = ?
+			ORDER BY pull_request_id, user_id, created_at DESC, id DESC`, prIDs, domain.AssignmentActionAssigned).
+		Scan(&assignmentModels).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find latest reviewer assignments: %w", err)
+	}
+
+	return models.ReviewerAssignmentsToDomain(assignmentModels), nil
+}
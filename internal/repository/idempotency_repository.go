@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/database/models"
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve stores record unless a live record with the same key exists. It
+// returns the existing record and false in that case.
+func (r *IdempotencyRepository) Reserve(
+	ctx context.Context, record domain.IdempotencyRecord,
+) (*domain.IdempotencyRecord, bool, error) {
+	err := r.db.WithContext(ctx).
+		Where("idempotency_key = ? AND expires_at <= ?", record.Key, record.CreatedAt).
+		Delete(&models.IdempotencyKey{}).Error
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to expire idempotency key: %w", err)
+	}
+
+	keyModel := models.IdempotencyKeyFromDomain(record)
+	keyModel.StatusCode = 0
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&keyModel)
+	if result.Error != nil {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		reserved := models.IdempotencyKeyToDomain(keyModel)
+		return &reserved, true, nil
+	}
+
+	var existing models.IdempotencyKey
+	if err := r.db.WithContext(ctx).Where("idempotency_key = ?", record.Key).First(&existing).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to find idempotency key: %w", err)
+	}
+
+	stored := models.IdempotencyKeyToDomain(existing)
+	return &stored, false, nil
+}
+
+func (r *IdempotencyRepository) Complete(
+	ctx context.Context, key string, statusCode int, contentType string, body []byte,
+) error {
+	err := r.db.WithContext(ctx).
+		Model(&models.IdempotencyKey{}).
+		Where("idempotency_key = ?", key).
+		Updates(map[string]interface{}{
+			"status_code":   statusCode,
+			"content_type":  contentType,
+			"response_body": body,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) Release(ctx context.Context, key string) error {
+	err := r.db.WithContext(ctx).
+		Where("idempotency_key = ? AND status_code = 0", key).
+		Delete(&models.IdempotencyKey{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
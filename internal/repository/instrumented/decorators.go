@@ -0,0 +1,292 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+type userRepository struct {
+	next     domain.UserRepository
+	observer Observer
+}
+
+func (r userRepository) Create(ctx context.Context, user domain.User) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "users", "Create")
+	defer func() { done(err) }()
+	return r.next.Create(ctx, user)
+}
+
+func (r userRepository) FindOne(ctx context.Context, filter domain.UserFilter) (_ *domain.User, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "users", "FindOne")
+	defer func() { done(err) }()
+	return r.next.FindOne(ctx, filter)
+}
+
+func (r userRepository) Update(ctx context.Context, user *domain.User) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "users", "Update")
+	defer func() { done(err) }()
+	return r.next.Update(ctx, user)
+}
+
+func (r userRepository) FindAll(ctx context.Context, filter domain.UserFilter) (_ []domain.User, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "users", "FindAll")
+	defer func() { done(err) }()
+	return r.next.FindAll(ctx, filter)
+}
+
+func (r userRepository) SetActive(ctx context.Context, userIDs []string, isActive bool) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "users", "SetActive")
+	defer func() { done(err) }()
+	return r.next.SetActive(ctx, userIDs, isActive)
+}
+
+type teamRepository struct {
+	next     domain.TeamRepository
+	observer Observer
+}
+
+func (r teamRepository) Create(ctx context.Context, team domain.Team) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "teams", "Create")
+	defer func() { done(err) }()
+	return r.next.Create(ctx, team)
+}
+
+func (r teamRepository) FindOne(ctx context.Context, filter domain.TeamFilter) (_ *domain.Team, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "teams", "FindOne")
+	defer func() { done(err) }()
+	return r.next.FindOne(ctx, filter)
+}
+
+func (r teamRepository) Exists(ctx context.Context, filter domain.TeamFilter) (_ bool, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "teams", "Exists")
+	defer func() { done(err) }()
+	return r.next.Exists(ctx, filter)
+}
+
+func (r teamRepository) SetFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "teams", "SetFallbackTeams")
+	defer func() { done(err) }()
+	return r.next.SetFallbackTeams(ctx, teamName, fallbackTeams)
+}
+
+func (r teamRepository) FindFallbackTeams(ctx context.Context, teamName string) (_ []string, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "teams", "FindFallbackTeams")
+	defer func() { done(err) }()
+	return r.next.FindFallbackTeams(ctx, teamName)
+}
+
+func (r teamRepository) LockMembers(ctx context.Context, teamName string) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "teams", "LockMembers")
+	defer func() { done(err) }()
+	return r.next.LockMembers(ctx, teamName)
+}
+
+func (r teamRepository) HasPullRequests(ctx context.Context, teamName string) (_ bool, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "teams", "HasPullRequests")
+	defer func() { done(err) }()
+	return r.next.HasPullRequests(ctx, teamName)
+}
+
+func (r teamRepository) Archive(ctx context.Context, teamName string) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "teams", "Archive")
+	defer func() { done(err) }()
+	return r.next.Archive(ctx, teamName)
+}
+
+func (r teamRepository) Delete(ctx context.Context, teamName string) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "teams", "Delete")
+	defer func() { done(err) }()
+	return r.next.Delete(ctx, teamName)
+}
+
+type prRepository struct {
+	next     domain.PRRepository
+	observer Observer
+}
+
+func (r prRepository) Create(ctx context.Context, pr domain.PullRequest) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "pull_requests", "Create")
+	defer func() { done(err) }()
+	return r.next.Create(ctx, pr)
+}
+
+func (r prRepository) FindOne(ctx context.Context, filter domain.PRFilter) (_ *domain.PullRequest, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "pull_requests", "FindOne")
+	defer func() { done(err) }()
+	return r.next.FindOne(ctx, filter)
+}
+
+func (r prRepository) Update(ctx context.Context, pr *domain.PullRequest) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "pull_requests", "Update")
+	defer func() { done(err) }()
+	return r.next.Update(ctx, pr)
+}
+
+func (r prRepository) UpdateReviewers(ctx context.Context, prs []domain.PullRequest) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "pull_requests", "UpdateReviewers")
+	defer func() { done(err) }()
+	return r.next.UpdateReviewers(ctx, prs)
+}
+
+func (r prRepository) FindAll(ctx context.Context, filter domain.PRFilter) (_ []domain.PullRequest, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "pull_requests", "FindAll")
+	defer func() { done(err) }()
+	return r.next.FindAll(ctx, filter)
+}
+
+func (r prRepository) Exists(ctx context.Context, filter domain.PRFilter) (_ bool, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "pull_requests", "Exists")
+	defer func() { done(err) }()
+	return r.next.Exists(ctx, filter)
+}
+
+func (r prRepository) FindByReviewer(ctx context.Context, userID string) (_ []domain.PullRequest, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "pull_requests", "FindByReviewer")
+	defer func() { done(err) }()
+	return r.next.FindByReviewer(ctx, userID)
+}
+
+func (r prRepository) CountOpenReviews(ctx context.Context, userIDs []string) (_ map[string]int, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "pull_requests", "CountOpenReviews")
+	defer func() { done(err) }()
+	return r.next.CountOpenReviews(ctx, userIDs)
+}
+
+type reviewRepository struct {
+	next     domain.ReviewRepository
+	observer Observer
+}
+
+func (r reviewRepository) Create(ctx context.Context, review domain.Review) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "reviews", "Create")
+	defer func() { done(err) }()
+	return r.next.Create(ctx, review)
+}
+
+func (r reviewRepository) FindLatest(ctx context.Context, prIDs []string) (_ []domain.Review, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "reviews", "FindLatest")
+	defer func() { done(err) }()
+	return r.next.FindLatest(ctx, prIDs)
+}
+
+type assignmentRepository struct {
+	next     domain.AssignmentRepository
+	observer Observer
+}
+
+func (r assignmentRepository) Create(ctx context.Context, assignments []domain.ReviewerAssignment) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "assignments", "Create")
+	defer func() { done(err) }()
+	return r.next.Create(ctx, assignments)
+}
+
+func (r assignmentRepository) FindByPR(ctx context.Context, prID string) (_ []domain.ReviewerAssignment, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "assignments", "FindByPR")
+	defer func() { done(err) }()
+	return r.next.FindByPR(ctx, prID)
+}
+
+func (r assignmentRepository) FindLatestAssigned(
+	ctx context.Context, prIDs []string,
+) (_ []domain.ReviewerAssignment, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "assignments", "FindLatestAssigned")
+	defer func() { done(err) }()
+	return r.next.FindLatestAssigned(ctx, prIDs)
+}
+
+type statsRepository struct {
+	next     domain.StatsRepository
+	observer Observer
+}
+
+func (r statsRepository) UserAssignments(
+	ctx context.Context, filter domain.StatsFilter,
+) (_ []domain.UserAssignmentStats, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "stats", "UserAssignments")
+	defer func() { done(err) }()
+	return r.next.UserAssignments(ctx, filter)
+}
+
+func (r statsRepository) OpenPRsByTeam(ctx context.Context, filter domain.StatsFilter) (_ []domain.TeamPRStats, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "stats", "OpenPRsByTeam")
+	defer func() { done(err) }()
+	return r.next.OpenPRsByTeam(ctx, filter)
+}
+
+func (r statsRepository) ReviewersPerPR(
+	ctx context.Context, filter domain.StatsFilter,
+) (_ []domain.PRReviewerStats, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "stats", "ReviewersPerPR")
+	defer func() { done(err) }()
+	return r.next.ReviewersPerPR(ctx, filter)
+}
+
+func (r statsRepository) AverageTimeToMerge(ctx context.Context, filter domain.StatsFilter) (_ *float64, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "stats", "AverageTimeToMerge")
+	defer func() { done(err) }()
+	return r.next.AverageTimeToMerge(ctx, filter)
+}
+
+type idempotencyRepository struct {
+	next     domain.IdempotencyRepository
+	observer Observer
+}
+
+func (r idempotencyRepository) Reserve(
+	ctx context.Context, record domain.IdempotencyRecord,
+) (_ *domain.IdempotencyRecord, _ bool, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "idempotency", "Reserve")
+	defer func() { done(err) }()
+	return r.next.Reserve(ctx, record)
+}
+
+func (r idempotencyRepository) Complete(
+	ctx context.Context, key string, statusCode int, contentType string, body []byte,
+) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "idempotency", "Complete")
+	defer func() { done(err) }()
+	return r.next.Complete(ctx, key, statusCode, contentType, body)
+}
+
+func (r idempotencyRepository) Release(ctx context.Context, key string) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "idempotency", "Release")
+	defer func() { done(err) }()
+	return r.next.Release(ctx, key)
+}
+
+func (r idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (_ int64, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "idempotency", "DeleteExpired")
+	defer func() { done(err) }()
+	return r.next.DeleteExpired(ctx, now)
+}
+
+type tokenRepository struct {
+	next     domain.TokenRepository
+	observer Observer
+}
+
+func (r tokenRepository) Create(ctx context.Context, token *domain.APIToken) (err error) {
+	ctx, done := r.observer.StartQuery(ctx, "tokens", "Create")
+	defer func() { done(err) }()
+	return r.next.Create(ctx, token)
+}
+
+func (r tokenRepository) FindByHash(ctx context.Context, tokenHash string) (_ *domain.APIToken, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "tokens", "FindByHash")
+	defer func() { done(err) }()
+	return r.next.FindByHash(ctx, tokenHash)
+}
+
+func (r tokenRepository) FindAll(ctx context.Context) (_ []domain.APIToken, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "tokens", "FindAll")
+	defer func() { done(err) }()
+	return r.next.FindAll(ctx)
+}
+
+func (r tokenRepository) Revoke(ctx context.Context, id int64, revokedAt time.Time) (_ bool, err error) {
+	ctx, done := r.observer.StartQuery(ctx, "tokens", "Revoke")
+	defer func() { done(err) }()
+	return r.next.Revoke(ctx, id, revokedAt)
+}
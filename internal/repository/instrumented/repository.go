@@ -0,0 +1,69 @@
+// Package instrumented decorates repositories so that every storage call is
+// reported to an Observer.
+package instrumented
+
+import (
+	"context"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+// Observer is told when a repository call starts and receives its error when
+// it ends. The returned context is passed on to the wrapped repository.
+type Observer interface {
+	StartQuery(ctx context.Context, repository, method string) (context.Context, func(err error))
+}
+
+func NewRepositories(repos domain.Repositories, observer Observer) domain.Repositories {
+	return domain.Repositories{
+		Users:       userRepository{next: repos.Users, observer: observer},
+		Teams:       teamRepository{next: repos.Teams, observer: observer},
+		PRs:         prRepository{next: repos.PRs, observer: observer},
+		Reviews:     reviewRepository{next: repos.Reviews, observer: observer},
+		Assignments: assignmentRepository{next: repos.Assignments, observer: observer},
+		Stats:       statsRepository{next: repos.Stats, observer: observer},
+		Idempotency: idempotencyRepository{next: repos.Idempotency, observer: observer},
+		Tokens:      tokenRepository{next: repos.Tokens, observer: observer},
+	}
+}
+
+// TxManager reports every transaction as a call to "tx" and instruments the
+// repositories handed to transaction callbacks.
+type TxManager struct {
+	next     domain.TxManager
+	observer Observer
+}
+
+func NewTxManager(txManager domain.TxManager, observer Observer) *TxManager {
+	return &TxManager{next: txManager, observer: observer}
+}
+
+func (m *TxManager) WithinTx(
+	ctx context.Context, fn func(ctx context.Context, repos domain.Repositories) error,
+) (err error) {
+	ctx, done := m.observer.StartQuery(ctx, "tx", "WithinTx")
+	defer func() { done(err) }()
+	return m.next.WithinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		return fn(ctx, NewRepositories(repos, m.observer))
+	})
+}
+
+// Observers reports every call to each of observers in order; the context
+// returned by one is passed to the next.
+func Observers(observers ...Observer) Observer {
+	return multiObserver(observers)
+}
+
+type multiObserver []Observer
+
+func (m multiObserver) StartQuery(ctx context.Context, repository, method string) (context.Context, func(err error)) {
+	dones := make([]func(error), len(m))
+	for i, observer := range m {
+		ctx, dones[i] = observer.StartQuery(ctx, repository, method)
+	}
+	return ctx, func(err error) {
+		for i := len(dones) - 1; i >= 0; i-- {
+			dones[i](err)
+		}
+	}
+}
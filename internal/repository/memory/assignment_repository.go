@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+type AssignmentRepository struct {
+	access
+}
+
+func (r *AssignmentRepository) Create(ctx context.Context, assignments []domain.ReviewerAssignment) error {
+	r.write(func(d *data) {
+		d.assignments = append(d.assignments, assignments...)
+	})
+	return nil
+}
+
+func (r *AssignmentRepository) FindByPR(ctx context.Context, prID string) ([]domain.ReviewerAssignment, error) {
+	assignments := []domain.ReviewerAssignment{}
+	r.read(func(d *data) {
+		for _, assignment := range d.assignments {
+			if assignment.PullRequestID == prID {
+				assignments = append(assignments, assignment)
+			}
+		}
+	})
+	return assignments, nil
+}
+
+func (r *AssignmentRepository) FindLatestAssigned(
+	ctx context.Context, prIDs []string,
+) ([]domain.ReviewerAssignment, error) {
+	type key struct{ prID, userID string }
+
+	latest := make(map[key]domain.ReviewerAssignment)
+	r.read(func(d *data) {
+		for _, assignment := range d.assignments {
+			if assignment.Action != domain.AssignmentActionAssigned || !containsString(prIDs, assignment.PullRequestID) {
+				continue
+			}
+			k := key{assignment.PullRequestID, assignment.UserID}
+			if existing, ok := latest[k]; !ok || !assignment.CreatedAt.Before(existing.CreatedAt) {
+				latest[k] = assignment
+			}
+		}
+	})
+
+	assignments := make([]domain.ReviewerAssignment, 0, len(latest))
+	for _, assignment := range latest {
+		assignments = append(assignments, assignment)
+	}
+	return assignments, nil
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+type IdempotencyRepository struct {
+	access
+}
+
+func (r *IdempotencyRepository) Reserve(
+	ctx context.Context, record domain.IdempotencyRecord,
+) (*domain.IdempotencyRecord, bool, error) {
+	var stored domain.IdempotencyRecord
+	reserved := false
+	r.write(func(d *data) {
+		existing, ok := d.idempotency[record.Key]
+		if ok && existing.ExpiresAt.After(record.CreatedAt) {
+			stored = existing
+			return
+		}
+		record.StatusCode = 0
+		d.idempotency[record.Key] = record
+		stored = record
+		reserved = true
+	})
+	return &stored, reserved, nil
+}
+
+func (r *IdempotencyRepository) Complete(
+	ctx context.Context, key string, statusCode int, contentType string, body []byte,
+) error {
+	r.write(func(d *data) {
+		record, ok := d.idempotency[key]
+		if !ok {
+			return
+		}
+		record.StatusCode = statusCode
+		record.ContentType = contentType
+		record.ResponseBody = append([]byte{}, body...)
+		d.idempotency[key] = record
+	})
+	return nil
+}
+
+func (r *IdempotencyRepository) Release(ctx context.Context, key string) error {
+	r.write(func(d *data) {
+		if record, ok := d.idempotency[key]; ok && record.StatusCode == 0 {
+			delete(d.idempotency, key)
+		}
+	})
+	return nil
+}
+
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	r.write(func(d *data) {
+		for key, record := range d.idempotency {
+			if !record.ExpiresAt.After(now) {
+				delete(d.idempotency, key)
+				deleted++
+			}
+		}
+	})
+	return deleted, nil
+}
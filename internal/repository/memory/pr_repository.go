@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+type PRRepository struct {
+	access
+}
+
+func (r *PRRepository) Create(ctx context.Context, pr domain.PullRequest) error {
+	var err error
+	r.write(func(d *data) {
+		if _, ok := d.prs[pr.PullRequestID]; ok {
+			err = fmt.Errorf("%w: pull request %s", domain.ErrDuplicateKey, pr.PullRequestID)
+			return
+		}
+		pr.Version = 1
+		d.prs[pr.PullRequestID] = copyPR(pr)
+	})
+	return err
+}
+
+func (r *PRRepository) FindOne(ctx context.Context, filter domain.PRFilter) (*domain.PullRequest, error) {
+	filter.Limit = 1
+	prs, err := r.FindAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(prs) == 0 {
+		return nil, fmt.Errorf("pull request not found")
+	}
+	return &prs[0], nil
+}
+
+func (r *PRRepository) Update(ctx context.Context, pr *domain.PullRequest) error {
+	var err error
+	r.write(func(d *data) {
+		stored, ok := d.prs[pr.PullRequestID]
+		if !ok || stored.Version != pr.Version {
+			err = domain.ErrVersionConflict
+			return
+		}
+		pr.Version++
+		d.prs[pr.PullRequestID] = copyPR(*pr)
+	})
+	return err
+}
+
+func (r *PRRepository) UpdateReviewers(ctx context.Context, prs []domain.PullRequest) error {
+	var err error
+	r.write(func(d *data) {
+		for _, pr := range prs {
+			stored, ok := d.prs[pr.PullRequestID]
+			if !ok || stored.Version != pr.Version {
+				err = domain.ErrVersionConflict
+				return
+			}
+		}
+		for i := range prs {
+			stored := d.prs[prs[i].PullRequestID]
+			stored.AssignedReviewers = copyStrings(prs[i].AssignedReviewers)
+			stored.FallbackReviewers = copyStrings(prs[i].FallbackReviewers)
+			stored.Version++
+			d.prs[prs[i].PullRequestID] = copyPR(stored)
+			prs[i].Version = stored.Version
+		}
+	})
+	return err
+}
+
+func (r *PRRepository) FindAll(ctx context.Context, filter domain.PRFilter) ([]domain.PullRequest, error) {
+	prs := []domain.PullRequest{}
+	r.read(func(d *data) {
+		for _, pr := range d.prs {
+			if matchPR(d, pr, filter) {
+				prs = append(prs, copyPR(pr))
+			}
+		}
+	})
+
+	sort.Slice(prs, func(i, j int) bool {
+		return comparePRs(prs[i], prs[j]) > 0
+	})
+	if filter.Limit > 0 && len(prs) > filter.Limit {
+		prs = prs[:filter.Limit]
+	}
+	return prs, nil
+}
+
+func (r *PRRepository) Exists(ctx context.Context, filter domain.PRFilter) (bool, error) {
+	exists := false
+	r.read(func(d *data) {
+		for _, pr := range d.prs {
+			if matchPR(d, pr, filter) {
+				exists = true
+				return
+			}
+		}
+	})
+	return exists, nil
+}
+
+func (r *PRRepository) FindByReviewer(ctx context.Context, userID string) ([]domain.PullRequest, error) {
+	return r.FindAll(ctx, domain.PRFilter{ReviewerID: &userID})
+}
+
+func (r *PRRepository) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(userIDs))
+	r.read(func(d *data) {
+		for _, pr := range d.prs {
+			if pr.Status != domain.PRStatusOpen {
+				continue
+			}
+			for _, reviewerID := range pr.AssignedReviewers {
+				if containsString(userIDs, reviewerID) {
+					counts[reviewerID]++
+				}
+			}
+		}
+	})
+	return counts, nil
+}
+
+func matchPR(d *data, pr domain.PullRequest, filter domain.PRFilter) bool {
+	if filter.PullRequestID != nil && pr.PullRequestID != *filter.PullRequestID {
+		return false
+	}
+	if filter.AuthorID != nil && pr.AuthorID != *filter.AuthorID {
+		return false
+	}
+	if filter.Status != nil && pr.Status != *filter.Status {
+		return false
+	}
+	if filter.ReviewerID != nil && !containsString(pr.AssignedReviewers, *filter.ReviewerID) {
+		return false
+	}
+	if len(filter.ReviewerIDs) > 0 && !containsAny(pr.AssignedReviewers, filter.ReviewerIDs) {
+		return false
+	}
+	if filter.TeamName != nil && d.users[pr.AuthorID].TeamName != *filter.TeamName {
+		return false
+	}
+	if filter.CreatedFrom != nil && (pr.CreatedAt == nil || pr.CreatedAt.Before(*filter.CreatedFrom)) {
+		return false
+	}
+	if filter.CreatedTo != nil && (pr.CreatedAt == nil || !pr.CreatedAt.Before(*filter.CreatedTo)) {
+		return false
+	}
+	if filter.MergedFrom != nil && (pr.MergedAt == nil || pr.MergedAt.Before(*filter.MergedFrom)) {
+		return false
+	}
+	if filter.MergedTo != nil && (pr.MergedAt == nil || !pr.MergedAt.Before(*filter.MergedTo)) {
+		return false
+	}
+	if filter.After != nil {
+		after := domain.PullRequest{PullRequestID: filter.After.PullRequestID, CreatedAt: &filter.After.CreatedAt}
+		if comparePRs(pr, after) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// comparePRs orders pull requests by (created_at, pull_request_id), the same
+// key the Postgres repository pages on.
+func comparePRs(a, b domain.PullRequest) int {
+	switch {
+	case a.CreatedAt == nil && b.CreatedAt != nil:
+		return -1
+	case a.CreatedAt != nil && b.CreatedAt == nil:
+		return 1
+	case a.CreatedAt != nil && b.CreatedAt != nil && !a.CreatedAt.Equal(*b.CreatedAt):
+		if a.CreatedAt.Before(*b.CreatedAt) {
+			return -1
+		}
+		return 1
+	case a.PullRequestID < b.PullRequestID:
+		return -1
+	case a.PullRequestID > b.PullRequestID:
+		return 1
+	}
+	return 0
+}
+
+func containsAny(values, candidates []string) bool {
+	for _, candidate := range candidates {
+		if containsString(values, candidate) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+type ReviewRepository struct {
+	access
+}
+
+func (r *ReviewRepository) Create(ctx context.Context, review domain.Review) error {
+	r.write(func(d *data) {
+		d.reviews = append(d.reviews, review)
+	})
+	return nil
+}
+
+func (r *ReviewRepository) FindLatest(ctx context.Context, prIDs []string) ([]domain.Review, error) {
+	type key struct{ prID, reviewerID string }
+
+	latest := make(map[key]domain.Review)
+	r.read(func(d *data) {
+		for _, review := range d.reviews {
+			if !containsString(prIDs, review.PullRequestID) {
+				continue
+			}
+			k := key{review.PullRequestID, review.ReviewerID}
+			if existing, ok := latest[k]; !ok || !review.SubmittedAt.Before(existing.SubmittedAt) {
+				latest[k] = review
+			}
+		}
+	})
+
+	reviews := make([]domain.Review, 0, len(latest))
+	for _, review := range latest {
+		reviews = append(reviews, review)
+	}
+	sort.Slice(reviews, func(i, j int) bool {
+		if reviews[i].PullRequestID != reviews[j].PullRequestID {
+			return reviews[i].PullRequestID < reviews[j].PullRequestID
+		}
+		return reviews[i].ReviewerID < reviews[j].ReviewerID
+	})
+	return reviews, nil
+}
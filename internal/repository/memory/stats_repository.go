@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+type StatsRepository struct {
+	access
+}
+
+func (r *StatsRepository) UserAssignments(
+	ctx context.Context, filter domain.StatsFilter,
+) ([]domain.UserAssignmentStats, error) {
+	stats := []domain.UserAssignmentStats{}
+	r.read(func(d *data) {
+		for _, user := range findUsers(d, domain.UserFilter{TeamName: filter.TeamName}) {
+			userStats := domain.UserAssignmentStats{UserID: user.UserID, TeamName: user.TeamName}
+			for _, pr := range d.prs {
+				if pr.Status == domain.PRStatusOpen && inRange(pr.CreatedAt, filter) &&
+					containsString(pr.AssignedReviewers, user.UserID) {
+					userStats.CurrentAssignments++
+				}
+			}
+			for _, assignment := range d.assignments {
+				createdAt := assignment.CreatedAt
+				if assignment.UserID == user.UserID && assignment.Action == domain.AssignmentActionAssigned &&
+					inRange(&createdAt, filter) {
+					userStats.TotalAssignments++
+				}
+			}
+			stats = append(stats, userStats)
+		}
+	})
+	return stats, nil
+}
+
+func (r *StatsRepository) OpenPRsByTeam(ctx context.Context, filter domain.StatsFilter) ([]domain.TeamPRStats, error) {
+	stats := []domain.TeamPRStats{}
+	r.read(func(d *data) {
+		for name, team := range d.teams {
+			if team.ArchivedAt != nil || filter.TeamName != nil && name != *filter.TeamName {
+				continue
+			}
+			teamStats := domain.TeamPRStats{TeamName: name}
+			for _, pr := range d.prs {
+				if pr.Status == domain.PRStatusOpen && d.users[pr.AuthorID].TeamName == name &&
+					inRange(pr.CreatedAt, filter) {
+					teamStats.OpenPRs++
+				}
+			}
+			stats = append(stats, teamStats)
+		}
+	})
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].TeamName < stats[j].TeamName
+	})
+	return stats, nil
+}
+
+func (r *StatsRepository) ReviewersPerPR(ctx context.Context, filter domain.StatsFilter) ([]domain.PRReviewerStats, error) {
+	var prs []domain.PullRequest
+	r.read(func(d *data) {
+		for _, pr := range d.prs {
+			if matchStatsPR(d, pr, filter) && inRange(pr.CreatedAt, filter) {
+				prs = append(prs, pr)
+			}
+		}
+	})
+	sort.Slice(prs, func(i, j int) bool {
+		return comparePRs(prs[i], prs[j]) > 0
+	})
+	if filter.Limit > 0 && len(prs) > filter.Limit {
+		prs = prs[:filter.Limit]
+	}
+
+	stats := make([]domain.PRReviewerStats, len(prs))
+	for i, pr := range prs {
+		stats[i] = domain.PRReviewerStats{
+			PullRequestID: pr.PullRequestID,
+			Status:        pr.Status,
+			Reviewers:     len(pr.AssignedReviewers),
+		}
+	}
+	return stats, nil
+}
+
+func (r *StatsRepository) AverageTimeToMerge(ctx context.Context, filter domain.StatsFilter) (*float64, error) {
+	var total float64
+	var count int
+	r.read(func(d *data) {
+		for _, pr := range d.prs {
+			if pr.Status != domain.PRStatusMerged || pr.MergedAt == nil || pr.CreatedAt == nil {
+				continue
+			}
+			if matchStatsPR(d, pr, filter) && inRange(pr.MergedAt, filter) {
+				total += pr.MergedAt.Sub(*pr.CreatedAt).Seconds()
+				count++
+			}
+		}
+	})
+	if count == 0 {
+		return nil, nil
+	}
+
+	average := total / float64(count)
+	return &average, nil
+}
+
+func matchStatsPR(d *data, pr domain.PullRequest, filter domain.StatsFilter) bool {
+	return filter.TeamName == nil || d.users[pr.AuthorID].TeamName == *filter.TeamName
+}
+
+func inRange(t *time.Time, filter domain.StatsFilter) bool {
+	if filter.From == nil && filter.To == nil {
+		return true
+	}
+	if t == nil {
+		return false
+	}
+	if filter.From != nil && t.Before(*filter.From) {
+		return false
+	}
+	return filter.To == nil || t.Before(*filter.To)
+}
@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+// Store keeps all data in process memory. Every repository call takes the
+// store lock; WithinTx holds the write lock for the whole transaction and
+// restores a snapshot if fn fails, so transactions are serialisable.
+type Store struct {
+	mu   sync.RWMutex
+	data *data
+}
+
+type data struct {
+	teams         map[string]domain.Team
+	fallbackTeams map[string][]string
+	users         map[string]domain.User
+	prs           map[string]domain.PullRequest
+	reviews       []domain.Review
+	assignments   []domain.ReviewerAssignment
+	idempotency   map[string]domain.IdempotencyRecord
+	tokens        []domain.APIToken
+	nextTokenID   int64
+}
+
+func NewStore() *Store {
+	return &Store{data: newData()}
+}
+
+func newData() *data {
+	return &data{
+		teams:         make(map[string]domain.Team),
+		fallbackTeams: make(map[string][]string),
+		users:         make(map[string]domain.User),
+		prs:           make(map[string]domain.PullRequest),
+		idempotency:   make(map[string]domain.IdempotencyRecord),
+	}
+}
+
+func (d *data) clone() *data {
+	c := newData()
+	for name, team := range d.teams {
+		c.teams[name] = team
+	}
+	for name, fallbackTeams := range d.fallbackTeams {
+		c.fallbackTeams[name] = copyStrings(fallbackTeams)
+	}
+	for id, user := range d.users {
+		c.users[id] = user
+	}
+	for id, pr := range d.prs {
+		c.prs[id] = copyPR(pr)
+	}
+	c.reviews = append(c.reviews, d.reviews...)
+	c.assignments = append(c.assignments, d.assignments...)
+	for key, record := range d.idempotency {
+		c.idempotency[key] = record
+	}
+	c.tokens = append(c.tokens, d.tokens...)
+	c.nextTokenID = d.nextTokenID
+	return c
+}
+
+// access is embedded by every repository. Repositories handed out inside a
+// transaction already run under the store lock and must not take it again.
+type access struct {
+	store *Store
+	inTx  bool
+}
+
+func (a access) read(fn func(d *data)) {
+	if !a.inTx {
+		a.store.mu.RLock()
+		defer a.store.mu.RUnlock()
+	}
+	fn(a.store.data)
+}
+
+func (a access) write(fn func(d *data)) {
+	if !a.inTx {
+		a.store.mu.Lock()
+		defer a.store.mu.Unlock()
+	}
+	fn(a.store.data)
+}
+
+func NewRepositories(store *Store) domain.Repositories {
+	return newRepositories(access{store: store})
+}
+
+func newRepositories(a access) domain.Repositories {
+	return domain.Repositories{
+		Users:       &UserRepository{access: a},
+		Teams:       &TeamRepository{access: a},
+		PRs:         &PRRepository{access: a},
+		Reviews:     &ReviewRepository{access: a},
+		Assignments: &AssignmentRepository{access: a},
+		Stats:       &StatsRepository{access: a},
+		Idempotency: &IdempotencyRepository{access: a},
+		Tokens:      &TokenRepository{access: a},
+	}
+}
+
+type TxManager struct {
+	store *Store
+}
+
+func NewTxManager(store *Store) *TxManager {
+	return &TxManager{store: store}
+}
+
+func (m *TxManager) WithinTx(
+	ctx context.Context, fn func(ctx context.Context, repos domain.Repositories) error,
+) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	snapshot := m.store.data.clone()
+	committed := false
+	defer func() {
+		if !committed {
+			m.store.data = snapshot
+		}
+	}()
+
+	if err := fn(ctx, newRepositories(access{store: m.store, inTx: true})); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+func copyStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string{}, values...)
+}
+
+func copyPR(pr domain.PullRequest) domain.PullRequest {
+	pr.AssignedReviewers = copyStrings(pr.AssignedReviewers)
+	if pr.AssignedReviewers == nil {
+		pr.AssignedReviewers = []string{}
+	}
+	pr.FallbackReviewers = copyStrings(pr.FallbackReviewers)
+	pr.Reviews = nil
+	return pr
+}
+
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+	"github.com/nikitaenmi/AvitoTest/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithinTxRollsBackOnPanic(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	repos := memory.NewRepositories(store)
+	txManager := memory.NewTxManager(store)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = txManager.WithinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+			require.NoError(t, repos.Teams.Create(ctx, domain.Team{TeamName: "backend"}))
+			panic("boom")
+		})
+	})
+
+	exists, err := repos.Teams.Exists(ctx, domain.TeamFilter{TeamName: ptr("backend")})
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	err = txManager.WithinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		return repos.Teams.Create(ctx, domain.Team{TeamName: "backend"})
+	})
+	require.NoError(t, err)
+}
+
+func ptr(value string) *string {
+	return &value
+}
@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+type TeamRepository struct {
+	access
+}
+
+func (r *TeamRepository) Create(ctx context.Context, team domain.Team) error {
+	var err error
+	r.write(func(d *data) {
+		if _, ok := d.teams[team.TeamName]; ok {
+			err = fmt.Errorf("%w: team %s", domain.ErrDuplicateKey, team.TeamName)
+			return
+		}
+		team.Members = nil
+		team.FallbackTeams = nil
+		d.teams[team.TeamName] = team
+	})
+	return err
+}
+
+func (r *TeamRepository) FindOne(ctx context.Context, filter domain.TeamFilter) (*domain.Team, error) {
+	var team *domain.Team
+	r.read(func(d *data) {
+		for name, stored := range d.teams {
+			if !matchTeam(stored, filter) {
+				continue
+			}
+			stored.FallbackTeams = copyStrings(d.fallbackTeams[name])
+			stored.Members = findUsers(d, domain.UserFilter{TeamName: &name})
+			team = &stored
+			return
+		}
+	})
+	if team == nil {
+		return nil, fmt.Errorf("team not found")
+	}
+	return team, nil
+}
+
+func (r *TeamRepository) Exists(ctx context.Context, filter domain.TeamFilter) (bool, error) {
+	exists := false
+	r.read(func(d *data) {
+		for _, team := range d.teams {
+			if matchTeam(team, filter) {
+				exists = true
+				return
+			}
+		}
+	})
+	return exists, nil
+}
+
+func (r *TeamRepository) SetFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) error {
+	r.write(func(d *data) {
+		if len(fallbackTeams) == 0 {
+			delete(d.fallbackTeams, teamName)
+			return
+		}
+		d.fallbackTeams[teamName] = copyStrings(fallbackTeams)
+	})
+	return nil
+}
+
+func (r *TeamRepository) FindFallbackTeams(ctx context.Context, teamName string) ([]string, error) {
+	var fallbackTeams []string
+	r.read(func(d *data) {
+		fallbackTeams = copyStrings(d.fallbackTeams[teamName])
+	})
+	return fallbackTeams, nil
+}
+
+// LockMembers does nothing: transactions already hold the store lock.
+func (r *TeamRepository) LockMembers(ctx context.Context, teamName string) error {
+	return nil
+}
+
+func (r *TeamRepository) HasPullRequests(ctx context.Context, teamName string) (bool, error) {
+	found := false
+	r.read(func(d *data) {
+		member := func(userID string) bool {
+			user, ok := d.users[userID]
+			return ok && user.TeamName == teamName
+		}
+
+		for _, pr := range d.prs {
+			if member(pr.AuthorID) {
+				found = true
+				return
+			}
+			for _, reviewerID := range pr.AssignedReviewers {
+				if member(reviewerID) {
+					found = true
+					return
+				}
+			}
+		}
+		for _, review := range d.reviews {
+			if member(review.ReviewerID) {
+				found = true
+				return
+			}
+		}
+		for _, assignment := range d.assignments {
+			if member(assignment.UserID) {
+				found = true
+				return
+			}
+		}
+	})
+	return found, nil
+}
+
+func (r *TeamRepository) Archive(ctx context.Context, teamName string) error {
+	r.write(func(d *data) {
+		if team, ok := d.teams[teamName]; ok && team.ArchivedAt == nil {
+			now := time.Now()
+			team.ArchivedAt = &now
+			d.teams[teamName] = team
+		}
+	})
+	return nil
+}
+
+func (r *TeamRepository) Delete(ctx context.Context, teamName string) error {
+	r.write(func(d *data) {
+		delete(d.teams, teamName)
+		delete(d.fallbackTeams, teamName)
+		for name, fallbackTeams := range d.fallbackTeams {
+			var kept []string
+			for _, fallbackTeam := range fallbackTeams {
+				if fallbackTeam != teamName {
+					kept = append(kept, fallbackTeam)
+				}
+			}
+			if len(kept) == 0 {
+				delete(d.fallbackTeams, name)
+			} else {
+				d.fallbackTeams[name] = kept
+			}
+		}
+		for userID, user := range d.users {
+			if user.TeamName == teamName {
+				delete(d.users, userID)
+			}
+		}
+		var tokens []domain.APIToken
+		for _, token := range d.tokens {
+			if _, ok := d.users[token.UserID]; token.UserID == "" || ok {
+				tokens = append(tokens, token)
+			}
+		}
+		d.tokens = tokens
+	})
+	return nil
+}
+
+func matchTeam(team domain.Team, filter domain.TeamFilter) bool {
+	if filter.TeamName != nil && team.TeamName != *filter.TeamName {
+		return false
+	}
+	if !filter.IncludeArchived && team.ArchivedAt != nil {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+type TokenRepository struct {
+	access
+}
+
+func (r *TokenRepository) Create(ctx context.Context, token *domain.APIToken) error {
+	var err error
+	r.write(func(d *data) {
+		for _, existing := range d.tokens {
+			if existing.TokenHash == token.TokenHash {
+				err = fmt.Errorf("%w: token %s", domain.ErrDuplicateKey, token.Name)
+				return
+			}
+		}
+		d.nextTokenID++
+		token.ID = d.nextTokenID
+		d.tokens = append(d.tokens, *token)
+	})
+	return err
+}
+
+func (r *TokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error) {
+	var token *domain.APIToken
+	r.read(func(d *data) {
+		for _, existing := range d.tokens {
+			if existing.TokenHash == tokenHash {
+				token = &existing
+				return
+			}
+		}
+	})
+	if token == nil {
+		return nil, fmt.Errorf("token %w", domain.ErrNotFound)
+	}
+	return token, nil
+}
+
+func (r *TokenRepository) FindAll(ctx context.Context) ([]domain.APIToken, error) {
+	var tokens []domain.APIToken
+	r.read(func(d *data) {
+		tokens = append([]domain.APIToken{}, d.tokens...)
+	})
+	return tokens, nil
+}
+
+func (r *TokenRepository) Revoke(ctx context.Context, id int64, revokedAt time.Time) (bool, error) {
+	revoked := false
+	r.write(func(d *data) {
+		for i, token := range d.tokens {
+			if token.ID == id && token.RevokedAt == nil {
+				d.tokens[i].RevokedAt = &revokedAt
+				revoked = true
+				return
+			}
+		}
+	})
+	return revoked, nil
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+type UserRepository struct {
+	access
+}
+
+func (r *UserRepository) Create(ctx context.Context, user domain.User) error {
+	var err error
+	r.write(func(d *data) {
+		if _, ok := d.users[user.UserID]; ok {
+			err = fmt.Errorf("%w: user %s", domain.ErrDuplicateKey, user.UserID)
+			return
+		}
+		d.users[user.UserID] = user
+	})
+	return err
+}
+
+func (r *UserRepository) FindOne(ctx context.Context, filter domain.UserFilter) (*domain.User, error) {
+	users, err := r.FindAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("user not found")
+	}
+	return &users[0], nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	r.write(func(d *data) {
+		d.users[user.UserID] = *user
+	})
+	return nil
+}
+
+func (r *UserRepository) FindAll(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	var users []domain.User
+	r.read(func(d *data) {
+		users = findUsers(d, filter)
+	})
+	return users, nil
+}
+
+func (r *UserRepository) SetActive(ctx context.Context, userIDs []string, isActive bool) error {
+	r.write(func(d *data) {
+		for _, userID := range userIDs {
+			if user, ok := d.users[userID]; ok {
+				user.IsActive = isActive
+				d.users[userID] = user
+			}
+		}
+	})
+	return nil
+}
+
+func findUsers(d *data, filter domain.UserFilter) []domain.User {
+	users := []domain.User{}
+	for _, user := range d.users {
+		if matchUser(user, filter) {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].UserID < users[j].UserID
+	})
+	return users
+}
+
+func matchUser(user domain.User, filter domain.UserFilter) bool {
+	if filter.UserID != nil && user.UserID != *filter.UserID {
+		return false
+	}
+	if len(filter.UserIDs) > 0 && !containsString(filter.UserIDs, user.UserID) {
+		return false
+	}
+	if filter.TeamName != nil && user.TeamName != *filter.TeamName {
+		return false
+	}
+	if filter.IsActive != nil && user.IsActive != *filter.IsActive {
+		return false
+	}
+	return true
+}
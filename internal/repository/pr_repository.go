@@ -3,10 +3,13 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/nikitaenmi/AvitoTest/internal/database/models"
 	"github.com/nikitaenmi/AvitoTest/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PRRepository struct {
@@ -18,8 +21,21 @@ func NewPRRepository(db *gorm.DB) *PRRepository {
 }
 
 func (r *PRRepository) Create(ctx context.Context, pr domain.PullRequest) error {
-	prModel := models.PullRequestFromDomain(pr)
-	return r.db.WithContext(ctx).Create(&prModel).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		prModel := models.PullRequestFromDomain(pr)
+		if err := tx.Create(&prModel).Error; err != nil {
+			return translateError(err)
+		}
+
+		reviewers := models.PRReviewersFromDomain(pr, time.Now())
+		if len(reviewers) == 0 {
+			return nil
+		}
+		if err := tx.Create(&reviewers).Error; err != nil {
+			return fmt.Errorf("failed to save reviewers: %w", err)
+		}
+		return nil
+	})
 }
 
 func (r *PRRepository) FindOne(ctx context.Context, filter domain.PRFilter) (*domain.PullRequest, error) {
@@ -31,25 +47,134 @@ func (r *PRRepository) FindOne(ctx context.Context, filter domain.PRFilter) (*do
 		return nil, fmt.Errorf("pull request not found: %w", err)
 	}
 
-	pr := models.PullRequestToDomain(prModel)
-	return &pr, nil
+	prs, err := r.withReviewers(ctx, []models.PullRequest{prModel})
+	if err != nil {
+		return nil, err
+	}
+	return &prs[0], nil
 }
 
 func (r *PRRepository) Update(ctx context.Context, pr *domain.PullRequest) error {
 	prModel := models.PullRequestFromDomain(*pr)
-	return r.db.WithContext(ctx).Save(&prModel).Error
+	prModel.Version++
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PullRequest{}).
+			Where("pull_request_id = ? AND version = ?", pr.PullRequestID, pr.Version).
+			Select("*").
+			Updates(&prModel)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update pull request: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrVersionConflict
+		}
+
+		return r.syncReviewers(tx, []domain.PullRequest{*pr})
+	})
+	if err != nil {
+		return err
+	}
+
+	pr.Version = prModel.Version
+	return nil
+}
+
+func (r *PRRepository) UpdateReviewers(ctx context.Context, prs []domain.PullRequest) error {
+	const batchSize = 1000
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(prs); start += batchSize {
+			end := start + batchSize
+			if end > len(prs) {
+				end = len(prs)
+			}
+
+			rows := make([]string, 0, end-start)
+			args := make([]interface{}, 0, 2*(end-start))
+			for _, pr := range prs[start:end] {
+				rows = append(rows, "(?, ?::bigint)")
+				args = append(args, pr.PullRequestID, pr.Version)
+			}
+
+			result := tx.Exec(`UPDATE pull_requests AS p
+				SET version = p.version + 1
+				FROM (VALUES `+strings.Join(rows, ", ")+`) AS v(pull_request_id, version)
+				WHERE p.pull_request_id = v.pull_request_id AND p.version = v.version`, args...)
+			if result.Error != nil {
+				return fmt.Errorf("failed to update reviewers: %w", result.Error)
+			}
+			if result.RowsAffected != int64(end-start) {
+				return domain.ErrVersionConflict
+			}
+
+			if err := r.syncReviewers(tx, prs[start:end]); err != nil {
+				return err
+			}
+		}
+
+		for i := range prs {
+			prs[i].Version++
+		}
+		return nil
+	})
+}
+
+// syncReviewers makes pr_reviewers match the given pull requests, keeping the
+// original assigned_at of reviewers that stay on a pull request.
+func (r *PRRepository) syncReviewers(tx *gorm.DB, prs []domain.PullRequest) error {
+	if len(prs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	prIDs := make([]string, len(prs))
+	var keep [][]interface{}
+	var reviewers []models.PRReviewer
+	for i, pr := range prs {
+		prIDs[i] = pr.PullRequestID
+		for _, reviewer := range models.PRReviewersFromDomain(pr, now) {
+			keep = append(keep, []interface{}{reviewer.PullRequestID, reviewer.UserID})
+			reviewers = append(reviewers, reviewer)
+		}
+	}
+
+	q := tx.Where("pull_request_id IN ?", prIDs)
+	if len(keep) > 0 {
+		q = q.Where("(pull_request_id, user_id) NOT IN ?", keep)
+	}
+	if err := q.Delete(&models.PRReviewer{}).Error; err != nil {
+		return fmt.Errorf("failed to remove reviewers: %w", err)
+	}
+
+	if len(reviewers) == 0 {
+		return nil
+	}
+
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "pull_request_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"slot", "is_fallback"}),
+	}).CreateInBatches(&reviewers, 500).Error
+	if err != nil {
+		return fmt.Errorf("failed to save reviewers: %w", err)
+	}
+	return nil
 }
 
 func (r *PRRepository) FindAll(ctx context.Context, filter domain.PRFilter) ([]domain.PullRequest, error) {
 	var prModels []models.PullRequest
 	q := r.db.WithContext(ctx)
 	q = r.buildFilterByParams(q, filter)
+	q = q.Order("created_at DESC").Order("pull_request_id DESC")
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
 
 	if err := q.Find(&prModels).Error; err != nil {
 		return nil, fmt.Errorf("failed to find pull requests: %w", err)
 	}
 
-	return models.PullRequestsToDomain(prModels), nil
+	return r.withReviewers(ctx, prModels)
 }
 
 func (r *PRRepository) Exists(ctx context.Context, filter domain.PRFilter) (bool, error) {
@@ -66,13 +191,67 @@ func (r *PRRepository) Exists(ctx context.Context, filter domain.PRFilter) (bool
 func (r *PRRepository) FindByReviewer(ctx context.Context, userID string) ([]domain.PullRequest, error) {
 	var prModels []models.PullRequest
 	err := r.db.WithContext(ctx).
-		Where("assigned_reviewers::jsonb @> ?", fmt.Sprintf(`["%s"]`, userID)).
+		Where("pull_request_id IN (SELECT pull_request_id FROM pr_reviewers WHERE user_id = ?)", userID).
 		Find(&prModels).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to find PRs by reviewer: %w", err)
 	}
 
-	return models.PullRequestsToDomain(prModels), nil
+	return r.withReviewers(ctx, prModels)
+}
+
+func (r *PRRepository) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(userIDs))
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		ReviewerID  string
+		OpenReviews int
+	}
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT r.user_id AS reviewer_id, COUNT(*) AS open_reviews
+			FROM pr_reviewers AS r
+			JOIN pull_requests AS p ON p.pull_request_id = r.pull_request_id
+			WHERE p.status = ? AND r.user_id IN ?
+			GROUP BY r.user_id`, domain.PRStatusOpen, userIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open reviews: %w", err)
+	}
+
+	for _, row := range rows {
+		counts[row.ReviewerID] = row.OpenReviews
+	}
+	return counts, nil
+}
+
+func (r *PRRepository) withReviewers(ctx context.Context, prModels []models.PullRequest) ([]domain.PullRequest, error) {
+	if len(prModels) == 0 {
+		return []domain.PullRequest{}, nil
+	}
+
+	prIDs := make([]string, len(prModels))
+	for i, prModel := range prModels {
+		prIDs[i] = prModel.PullRequestID
+	}
+
+	var reviewerModels []models.PRReviewer
+	err := r.db.WithContext(ctx).
+		Where("pull_request_id IN ?", prIDs).
+		Order("slot").
+		Find(&reviewerModels).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reviewers: %w", err)
+	}
+
+	reviewers := make(map[string][]models.PRReviewer, len(prModels))
+	for _, reviewer := range reviewerModels {
+		reviewers[reviewer.PullRequestID] = append(reviewers[reviewer.PullRequestID], reviewer)
+	}
+
+	return models.PullRequestsToDomain(prModels, reviewers), nil
 }
 
 func (r *PRRepository) buildFilterByParams(q *gorm.DB, filter domain.PRFilter) *gorm.DB {
@@ -85,5 +264,29 @@ func (r *PRRepository) buildFilterByParams(q *gorm.DB, filter domain.PRFilter) *
 	if filter.Status != nil {
 		q = q.Where("status = ?", *filter.Status)
 	}
+	if filter.ReviewerID != nil {
+		q = q.Where("pull_request_id IN (SELECT pull_request_id FROM pr_reviewers WHERE user_id = ?)", *filter.ReviewerID)
+	}
+	if len(filter.ReviewerIDs) > 0 {
+		q = q.Where("pull_request_id IN (SELECT pull_request_id FROM pr_reviewers WHERE user_id IN ?)", filter.ReviewerIDs)
+	}
+	if filter.TeamName != nil {
+		q = q.Where("author_id IN (SELECT user_id FROM users WHERE team_name = ?)", *filter.TeamName)
+	}
+	if filter.CreatedFrom != nil {
+		q = q.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		q = q.Where("created_at < ?", *filter.CreatedTo)
+	}
+	if filter.MergedFrom != nil {
+		q = q.Where("merged_at >= ?", *filter.MergedFrom)
+	}
+	if filter.MergedTo != nil {
+		q = q.Where("merged_at < ?", *filter.MergedTo)
+	}
+	if filter.After != nil {
+		q = q.Where("(created_at, pull_request_id) < (?, ?)", filter.After.CreatedAt, filter.After.PullRequestID)
+	}
 	return q
 }
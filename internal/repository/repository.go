@@ -1,6 +1,13 @@
 package repository
 
-import "gorm.io/gorm"
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+	"gorm.io/gorm"
+)
 
 type BaseRepository struct {
 	db *gorm.DB
@@ -9,3 +16,40 @@ type BaseRepository struct {
 func NewBaseRepository(db *gorm.DB) *BaseRepository {
 	return &BaseRepository{db: db}
 }
+
+func NewRepositories(db *gorm.DB) domain.Repositories {
+	userRepo := NewUserRepository(db)
+	return domain.Repositories{
+		Users:       userRepo,
+		Teams:       NewTeamRepository(db, userRepo),
+		PRs:         NewPRRepository(db),
+		Reviews:     NewReviewRepository(db),
+		Assignments: NewAssignmentRepository(db),
+		Stats:       NewStatsRepository(db),
+		Idempotency: NewIdempotencyRepository(db),
+		Tokens:      NewTokenRepository(db),
+	}
+}
+
+type TxManager struct {
+	db *gorm.DB
+}
+
+func NewTxManager(db *gorm.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+func (m *TxManager) WithinTx(
+	ctx context.Context, fn func(ctx context.Context, repos domain.Repositories) error,
+) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(ctx, NewRepositories(tx))
+	})
+}
+
+func translateError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return fmt.Errorf("%w: %v", domain.ErrDuplicateKey, err)
+	}
+	return err
+}
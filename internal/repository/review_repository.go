@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nikitaenmi/AvitoTest/internal/database/models"
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+	"gorm.io/gorm"
+)
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+func (r *ReviewRepository) Create(ctx context.Context, review domain.Review) error {
+	reviewModel := models.ReviewFromDomain(review)
+	return r.db.WithContext(ctx).Create(&reviewModel).Error
+}
+
+func (r *ReviewRepository) FindLatest(ctx context.Context, prIDs []string) ([]domain.Review, error) {
+	if len(prIDs) == 0 {
+		return nil, nil
+	}
+
+	var reviewModels []models.Review
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (pull_request_id, reviewer_id) *
+			FROM reviews
+			WHERE pull_request_id IN ?
+			ORDER BY pull_request_id, reviewer_id, submitted_at DESC, id DESC`, prIDs).
+		Scan(&reviewModels).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find latest reviews: %w", err)
+	}
+
+	return models.ReviewsToDomain(reviewModels), nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+	"gorm.io/gorm"
+)
+
+type StatsRepository struct {
+	db *gorm.DB
+}
+
+func NewStatsRepository(db *gorm.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+func (r *StatsRepository) UserAssignments(
+	ctx context.Context, filter domain.StatsFilter,
+) ([]domain.UserAssignmentStats, error) {
+	current := r.db.Table("pr_reviewers AS r").
+		Select("COUNT(*)").
+		Joins("JOIN pull_requests AS p ON p.pull_request_id = r.pull_request_id").
+		Where("r.user_id = u.user_id").
+		Where("p.status = ?", domain.PRStatusOpen)
+	current = r.buildRangeFilter(current, "p.created_at", filter)
+
+	total := r.db.Table("reviewer_assignments AS a").
+		Select("COUNT(*)").
+		Where("a.user_id = u.user_id").
+		Where("a.action = ?", domain.AssignmentActionAssigned)
+	total = r.buildRangeFilter(total, "a.created_at", filter)
+
+	q := r.db.WithContext(ctx).
+		Table("users AS u").
+		Select("u.user_id, u.team_name, (?) AS current_assignments, (?) AS total_assignments", current, total).
+		Order("u.user_id")
+	if filter.TeamName != nil {
+		q = q.Where("u.team_name = ?", *filter.TeamName)
+	}
+
+	var stats []domain.UserAssignmentStats
+	if err := q.Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate user assignments: %w", err)
+	}
+	return stats, nil
+}
+
+func (r *StatsRepository) OpenPRsByTeam(ctx context.Context, filter domain.StatsFilter) ([]domain.TeamPRStats, error) {
+	join := "LEFT JOIN pull_requests AS p ON p.author_id = u.user_id AND p.status = ?"
+	args := []interface{}{domain.PRStatusOpen}
+	if filter.From != nil {
+		join += " AND p.created_at >= ?"
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		join += " AND p.created_at < ?"
+		args = append(args, *filter.To)
+	}
+
+	q := r.db.WithContext(ctx).
+		Table("teams AS t").
+		Select("t.team_name, COUNT(p.pull_request_id) AS open_prs").
+		Joins("LEFT JOIN users AS u ON u.team_name = t.team_name").
+		Joins(join, args...).
+		Where("t.archived_at IS NULL").
+		Group("t.team_name").
+		Order("t.team_name")
+	if filter.TeamName != nil {
+		q = q.Where("t.team_name = ?", *filter.TeamName)
+	}
+
+	var stats []domain.TeamPRStats
+	if err := q.Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate open PRs by team: %w", err)
+	}
+	return stats, nil
+}
+
+func (r *StatsRepository) ReviewersPerPR(ctx context.Context, filter domain.StatsFilter) ([]domain.PRReviewerStats, error) {
+	q := r.db.WithContext(ctx).
+		Table("pull_requests AS p").
+		Select(`p.pull_request_id, p.status,
+			(SELECT COUNT(*) FROM pr_reviewers AS r WHERE r.pull_request_id = p.pull_request_id) AS reviewers`).
+		Order("p.created_at DESC").
+		Order("p.pull_request_id")
+	q = r.buildPRFilter(q, filter)
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+
+	var stats []domain.PRReviewerStats
+	if err := q.Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate reviewers per PR: %w", err)
+	}
+	return stats, nil
+}
+
+func (r *StatsRepository) AverageTimeToMerge(ctx context.Context, filter domain.StatsFilter) (*float64, error) {
+	q := r.db.WithContext(ctx).
+		Table("pull_requests AS p").
+		Select("AVG(EXTRACT(EPOCH FROM (p.merged_at - p.created_at)))").
+		Where("p.status = ?", domain.PRStatusMerged).
+		Where("p.merged_at IS NOT NULL AND p.created_at IS NOT NULL")
+	if filter.TeamName != nil {
+		q = q.Where("p.author_id IN (SELECT user_id FROM users WHERE team_name = ?)", *filter.TeamName)
+	}
+	q = r.buildRangeFilter(q, "p.merged_at", filter)
+
+	var average *float64
+	if err := q.Row().Scan(&average); err != nil {
+		return nil, fmt.Errorf("failed to aggregate time to merge: %w", err)
+	}
+	return average, nil
+}
+
+func (r *StatsRepository) buildPRFilter(q *gorm.DB, filter domain.StatsFilter) *gorm.DB {
+	if filter.TeamName != nil {
+		q = q.Where("p.author_id IN (SELECT user_id FROM users WHERE team_name = ?)", *filter.TeamName)
+	}
+	return r.buildRangeFilter(q, "p.created_at", filter)
+}
+
+func (r *StatsRepository) buildRangeFilter(q *gorm.DB, column string, filter domain.StatsFilter) *gorm.DB {
+	if filter.From != nil {
+		q = q.Where(column+" >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		q = q.Where(column+" < ?", *filter.To)
+	}
+	return q
+}
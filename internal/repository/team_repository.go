@@ -7,6 +7,7 @@ import (
 	"github.com/nikitaenmi/AvitoTest/internal/database/models"
 	"github.com/nikitaenmi/AvitoTest/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TeamRepository struct {
@@ -23,7 +24,7 @@ func NewTeamRepository(db *gorm.DB, userRepo *UserRepository) *TeamRepository {
 
 func (r *TeamRepository) Create(ctx context.Context, team domain.Team) error {
 	teamModel := models.TeamFromDomain(team)
-	return r.db.WithContext(ctx).Create(&teamModel).Error
+	return translateError(r.db.WithContext(ctx).Create(&teamModel).Error)
 }
 
 func (r *TeamRepository) FindOne(ctx context.Context, filter domain.TeamFilter) (*domain.Team, error) {
@@ -35,12 +36,17 @@ func (r *TeamRepository) FindOne(ctx context.Context, filter domain.TeamFilter)
 		return nil, fmt.Errorf("team not found: %w", err)
 	}
 
+	fallbackTeams, err := r.FindFallbackTeams(ctx, teamModel.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
 	members, err := r.userRepo.FindAll(ctx, domain.UserFilter{TeamName: &teamModel.TeamName})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get team members: %w", err)
 	}
 
-	team := models.TeamToDomain(teamModel, members)
+	team := models.TeamToDomain(teamModel, fallbackTeams, members)
 	return &team, nil
 }
 
@@ -55,7 +61,115 @@ func (r *TeamRepository) Exists(ctx context.Context, filter domain.TeamFilter) (
 	return count > 0, nil
 }
 
+func (r *TeamRepository) SetFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_name = ?", teamName).Delete(&models.TeamFallback{}).Error; err != nil {
+			return fmt.Errorf("failed to clear fallback teams: %w", err)
+		}
+
+		if len(fallbackTeams) == 0 {
+			return nil
+		}
+
+		fallbackModels := make([]models.TeamFallback, len(fallbackTeams))
+		for i, fallbackTeam := range fallbackTeams {
+			fallbackModels[i] = models.TeamFallback{
+				TeamName:         teamName,
+				FallbackTeamName: fallbackTeam,
+				Priority:         i,
+			}
+		}
+
+		if err := tx.Create(&fallbackModels).Error; err != nil {
+			return fmt.Errorf("failed to save fallback teams: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *TeamRepository) FindFallbackTeams(ctx context.Context, teamName string) ([]string, error) {
+	var fallbackTeams []string
+	err := r.db.WithContext(ctx).
+		Model(&models.TeamFallback{}).
+		Where("team_name = ?", teamName).
+		Order("priority").
+		Pluck("fallback_team_name", &fallbackTeams).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find fallback teams: %w", err)
+	}
+
+	return fallbackTeams, nil
+}
+
+// LockMembers locks the team's user rows until the transaction ends. Inserting
+// a row that references a user takes a key-share lock on it, so once the
+// members are locked no new pull request, reviewer or review can point at them
+// and a later check through HasPullRequests stays true until Delete.
+func (r *TeamRepository) LockMembers(ctx context.Context, teamName string) error {
+	var userIDs []string
+	err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("team_name = ?", teamName).
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return fmt.Errorf("failed to lock team members: %w", err)
+	}
+	return nil
+}
+
+// HasPullRequests reports whether any member of the team authored, reviews or
+// ever reviewed a pull request, i.e. whether deleting its users would break
+// foreign keys.
+func (r *TeamRepository) HasPullRequests(ctx context.Context, teamName string) (bool, error) {
+	members := r.db.Model(&models.User{}).Select("user_id").Where("team_name = ?", teamName)
+
+	for _, check := range []struct {
+		model  interface{}
+		column string
+	}{
+		{&models.PullRequest{}, "author_id"},
+		{&models.PRReviewer{}, "user_id"},
+		{&models.Review{}, "reviewer_id"},
+		{&models.ReviewerAssignment{}, "user_id"},
+	} {
+		var count int64
+		err := r.db.WithContext(ctx).
+			Model(check.model).
+			Where(check.column+" IN (?)", members).
+			Count(&count).Error
+		if err != nil {
+			return false, fmt.Errorf("failed to check team pull requests: %w", err)
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *TeamRepository) Archive(ctx context.Context, teamName string) error {
+	err := r.db.WithContext(ctx).Where("team_name = ?", teamName).Delete(&models.Team{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to archive team: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the team row; members and fallback links go with it through
+// ON DELETE CASCADE.
+func (r *TeamRepository) Delete(ctx context.Context, teamName string) error {
+	err := r.db.WithContext(ctx).Unscoped().Where("team_name = ?", teamName).Delete(&models.Team{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+	return nil
+}
+
 func (r *TeamRepository) buildFilterByParams(q *gorm.DB, filter domain.TeamFilter) *gorm.DB {
+	if filter.IncludeArchived {
+		q = q.Unscoped()
+	}
 	if filter.TeamName != nil {
 		q = q.Where("team_name = ?", *filter.TeamName)
 	}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/database/models"
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+	"gorm.io/gorm"
+)
+
+type TokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+func (r *TokenRepository) Create(ctx context.Context, token *domain.APIToken) error {
+	tokenModel := models.APITokenFromDomain(*token)
+	if err := r.db.WithContext(ctx).Create(&tokenModel).Error; err != nil {
+		return translateError(err)
+	}
+	token.ID = tokenModel.ID
+	return nil
+}
+
+func (r *TokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error) {
+	var tokenModel models.APIToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&tokenModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("token %w", domain.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to find token: %w", err)
+	}
+
+	token := models.APITokenToDomain(tokenModel)
+	return &token, nil
+}
+
+func (r *TokenRepository) FindAll(ctx context.Context) ([]domain.APIToken, error) {
+	var tokenModels []models.APIToken
+	if err := r.db.WithContext(ctx).Order("id").Find(&tokenModels).Error; err != nil {
+		return nil, fmt.Errorf("failed to find tokens: %w", err)
+	}
+	return models.APITokensToDomain(tokenModels), nil
+}
+
+// Revoke marks an active token as revoked and reports whether one was found.
+func (r *TokenRepository) Revoke(ctx context.Context, id int64, revokedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.APIToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to revoke token: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -19,7 +19,7 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 
 func (r *UserRepository) Create(ctx context.Context, user domain.User) error {
 	userModel := models.UserFromDomain(user)
-	return r.db.WithContext(ctx).Create(&userModel).Error
+	return translateError(r.db.WithContext(ctx).Create(&userModel).Error)
 }
 
 func (r *UserRepository) FindOne(ctx context.Context, filter domain.UserFilter) (*domain.User, error) {
@@ -52,10 +52,28 @@ func (r *UserRepository) FindAll(ctx context.Context, filter domain.UserFilter)
 	return models.UsersToDomain(userModels), nil
 }
 
+func (r *UserRepository) SetActive(ctx context.Context, userIDs []string, isActive bool) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("user_id IN ?", userIDs).
+		Update("is_active", isActive).Error
+	if err != nil {
+		return fmt.Errorf("failed to update user activity: %w", err)
+	}
+	return nil
+}
+
 func (r *UserRepository) buildFilterByParams(q *gorm.DB, filter domain.UserFilter) *gorm.DB {
 	if filter.UserID != nil {
 		q = q.Where("user_id = ?", *filter.UserID)
 	}
+	if len(filter.UserIDs) > 0 {
+		q = q.Where("user_id IN ?", filter.UserIDs)
+	}
 	if filter.TeamName != nil {
 		q = q.Where("team_name = ?", *filter.TeamName)
 	}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+func (s *Service) GetAssignmentHistory(ctx context.Context, filter domain.PRFilter) ([]domain.ReviewerAssignment, error) {
+	if filter.PullRequestID == nil || *filter.PullRequestID == "" {
+		return nil, domain.NewValidationError("pull request ID cannot be empty")
+	}
+
+	exists, err := s.repos.PRs.Exists(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, domain.NewNotFoundError("pull request")
+	}
+
+	return s.repos.Assignments.FindByPR(ctx, *filter.PullRequestID)
+}
+
+func newAssignments(
+	pr *domain.PullRequest, action, operation, reason string, userIDs []string,
+) []domain.ReviewerAssignment {
+	now := time.Now()
+	assignments := make([]domain.ReviewerAssignment, 0, len(userIDs))
+	for _, userID := range userIDs {
+		entryReason := reason
+		if action == domain.AssignmentActionAssigned && contains(pr.FallbackReviewers, userID) {
+			entryReason += "; drawn from fallback team"
+		}
+
+		assignments = append(assignments, domain.ReviewerAssignment{
+			PullRequestID: pr.PullRequestID,
+			UserID:        userID,
+			Action:        action,
+			Operation:     operation,
+			Reason:        entryReason,
+			CreatedAt:     now,
+		})
+	}
+	return assignments
+}
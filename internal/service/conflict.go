@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+	"github.com/nikitaenmi/AvitoTest/internal/tracing"
+)
+
+const maxConflictRetries = 3
+
+// retryOnConflict reruns fn while it fails because a pull request was modified
+// concurrently. fn must re-read everything it writes, so only operations whose
+// outcome does not depend on the state the caller saw should use it.
+func retryOnConflict(fn func() error) error {
+	for attempt := 0; attempt < maxConflictRetries; attempt++ {
+		err := fn()
+		if !errors.Is(err, domain.ErrVersionConflict) {
+			return err
+		}
+	}
+	return domain.NewConflictError()
+}
+
+// withinTxRetry runs fn in a transaction with retryOnConflict and notes the
+// retries on the request span, where each attempt shows up as a tx span.
+func (s *Service) withinTxRetry(
+	ctx context.Context, fn func(ctx context.Context, repos domain.Repositories) error,
+) error {
+	attempts := 0
+	err := retryOnConflict(func() error {
+		attempts++
+		return s.txManager.WithinTx(ctx, fn)
+	})
+	if attempts > 1 {
+		tracing.SpanFromContext(ctx).SetAttributes(tracing.Int("tx.conflict_retries", attempts-1))
+	}
+	return err
+}
+
+func conflictError(err error) error {
+	if errors.Is(err, domain.ErrVersionConflict) {
+		return domain.NewConflictError()
+	}
+	return err
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+func (s *Service) DeactivateTeamUsers(
+	ctx context.Context, filter domain.TeamFilter, userIDs []string,
+) (*domain.DeactivationReport, error) {
+	if filter.TeamName == nil || *filter.TeamName == "" {
+		return nil, domain.NewValidationError("team name cannot be empty")
+	}
+	if len(userIDs) == 0 {
+		return nil, domain.NewValidationError("user IDs cannot be empty")
+	}
+
+	var report *domain.DeactivationReport
+	err := s.withinTxRetry(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		report = newDeactivationReport()
+
+		exists, err := repos.Teams.Exists(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return domain.NewNotFoundError("team")
+		}
+
+		users, err := repos.Users.FindAll(ctx, domain.UserFilter{UserIDs: userIDs, TeamName: filter.TeamName})
+		if err != nil {
+			return err
+		}
+
+		found := make(map[string]bool, len(users))
+		for _, user := range users {
+			found[user.UserID] = true
+		}
+		for _, userID := range userIDs {
+			if !found[userID] {
+				return domain.NewNotFoundError("user " + userID + " in team")
+			}
+		}
+
+		if err := repos.Users.SetActive(ctx, userIDs, false); err != nil {
+			return err
+		}
+
+		return s.releaseReviewers(ctx, repos, users, releaseDeactivated, report)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordReleased(report)
+	return report, nil
+}
+
+// recordReleased reports the outcome of releaseReviewers once the transaction
+// has committed.
+func (s *Service) recordReleased(report *domain.DeactivationReport) {
+	s.events.ReviewersReassigned(len(report.Reassigned))
+	for range report.NoCandidate {
+		s.events.NoCandidate()
+	}
+}
+
+func newDeactivationReport() *domain.DeactivationReport {
+	return &domain.DeactivationReport{
+		DeactivatedUsers: []string{},
+		Reassigned:       []domain.ReviewerReplacement{},
+		NoCandidate:      []domain.ReviewerReplacement{},
+	}
+}
+
+// releaseCause describes why reviewers are released, for the assignment history.
+// With ownTeamOnly set, reviewers are only released from pull requests whose
+// author is in the team the reviewer is leaving.
+type releaseCause struct {
+	operation      string
+	unassignReason string
+	assignReason   string
+	ownTeamOnly    bool
+}
+
+var (
+	releaseDeactivated = releaseCause{
+		operation:      domain.AssignmentOperationDeactivate,
+		unassignReason: "reviewer deactivated",
+		assignReason:   "replacing deactivated reviewer",
+	}
+	releaseTeamArchived = releaseCause{
+		operation:      domain.AssignmentOperationDeactivate,
+		unassignReason: "reviewer's team archived",
+		assignReason:   "replacing reviewer from archived team",
+	}
+	releaseLeftTeam = releaseCause{
+		operation:      domain.AssignmentOperationMembership,
+		unassignReason: "reviewer left the team",
+		assignReason:   "replacing reviewer who left the team",
+	}
+	releaseMovedTeam = releaseCause{
+		operation:      domain.AssignmentOperationMembership,
+		unassignReason: "reviewer moved to another team",
+		assignReason:   "replacing reviewer who moved to another team",
+		ownTeamOnly:    true,
+	}
+)
+
+// releaseReviewers replaces the given users on every open pull request they
+// review, using one planner so that load is tracked across all pull requests,
+// and persists the result with batched writes. The users must already be
+// deactivated or moved out of their team, and carry the team they reviewed for.
+func (s *Service) releaseReviewers(
+	ctx context.Context, repos domain.Repositories, users []domain.User, cause releaseCause,
+	report *domain.DeactivationReport,
+) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	userTeams := make(map[string]string, len(users))
+	userIDs := make([]string, len(users))
+	for i, user := range users {
+		userTeams[user.UserID] = user.TeamName
+		userIDs[i] = user.UserID
+	}
+	report.DeactivatedUsers = append(report.DeactivatedUsers, userIDs...)
+
+	status := domain.PRStatusOpen
+	prs, err := repos.PRs.FindAll(ctx, domain.PRFilter{ReviewerIDs: userIDs, Status: &status})
+	if err != nil || len(prs) == 0 {
+		return err
+	}
+
+	authorTeams, err := s.authorTeams(ctx, repos, prs)
+	if err != nil {
+		return err
+	}
+
+	planner := s.newReviewerPlanner(repos)
+	var changed []domain.PullRequest
+	var assignments []domain.ReviewerAssignment
+	for i := range prs {
+		pr := &prs[i]
+		authorTeam := authorTeams[pr.AuthorID]
+
+		var released, replacements []string
+		for _, reviewerID := range append([]string{}, pr.AssignedReviewers...) {
+			reviewerTeam, deactivated := userTeams[reviewerID]
+			if !deactivated || (cause.ownTeamOnly && reviewerTeam != authorTeam.TeamName) {
+				continue
+			}
+
+			excludedUsers := append([]string{pr.AuthorID}, pr.AssignedReviewers...)
+			reviewers, fallbackReviewers, err := planner.pick(
+				ctx, authorTeam.TeamName, releaseTeams(reviewerTeam, authorTeam), excludedUsers, 1,
+			)
+			if err != nil {
+				return err
+			}
+
+			replacement := domain.ReviewerReplacement{PullRequestID: pr.PullRequestID, OldReviewerID: reviewerID}
+			pr.FallbackReviewers = without(pr.FallbackReviewers, reviewerID)
+			if len(reviewers) == 0 {
+				pr.AssignedReviewers = without(pr.AssignedReviewers, reviewerID)
+				report.NoCandidate = append(report.NoCandidate, replacement)
+			} else {
+				replacement.NewReviewerID = reviewers[0]
+				for j, assigned := range pr.AssignedReviewers {
+					if assigned == reviewerID {
+						pr.AssignedReviewers[j] = replacement.NewReviewerID
+					}
+				}
+				pr.FallbackReviewers = append(pr.FallbackReviewers, fallbackReviewers...)
+				replacements = append(replacements, replacement.NewReviewerID)
+				report.Reassigned = append(report.Reassigned, replacement)
+			}
+			released = append(released, reviewerID)
+		}
+		if len(released) == 0 {
+			continue
+		}
+		changed = append(changed, *pr)
+
+		assignments = append(assignments, newAssignments(pr, domain.AssignmentActionUnassigned,
+			cause.operation, cause.unassignReason, released)...)
+		assignments = append(assignments, newAssignments(pr, domain.AssignmentActionAssigned,
+			cause.operation, cause.assignReason, replacements)...)
+	}
+
+	if err := repos.PRs.UpdateReviewers(ctx, changed); err != nil {
+		return err
+	}
+
+	return repos.Assignments.Create(ctx, assignments)
+}
+
+func (s *Service) authorTeams(
+	ctx context.Context, repos domain.Repositories, prs []domain.PullRequest,
+) (map[string]*domain.Team, error) {
+	authorIDs := make([]string, 0, len(prs))
+	seen := make(map[string]bool, len(prs))
+	for _, pr := range prs {
+		if !seen[pr.AuthorID] {
+			seen[pr.AuthorID] = true
+			authorIDs = append(authorIDs, pr.AuthorID)
+		}
+	}
+
+	authors, err := repos.Users.FindAll(ctx, domain.UserFilter{UserIDs: authorIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	teams := make(map[string]*domain.Team)
+	authorTeams := make(map[string]*domain.Team, len(authors))
+	for _, author := range authors {
+		team, ok := teams[author.TeamName]
+		if !ok {
+			team, err = repos.Teams.FindOne(ctx, domain.TeamFilter{
+				TeamName:        &author.TeamName,
+				IncludeArchived: true,
+			})
+			if err != nil {
+				return nil, domain.NewNotFoundError("team")
+			}
+			teams[author.TeamName] = team
+		}
+		authorTeams[author.UserID] = team
+	}
+
+	for _, authorID := range authorIDs {
+		if _, ok := authorTeams[authorID]; !ok {
+			return nil, domain.NewNotFoundError("author")
+		}
+	}
+
+	return authorTeams, nil
+}
+
+// releaseTeams hands a released review to the remaining active members of the
+// team the reviewer belonged to first, and only then searches the author's team
+// and its fallbacks in order.
+func releaseTeams(reviewerTeam string, authorTeam *domain.Team) []string {
+	var teams []string
+	if reviewerTeam != "" {
+		teams = append(teams, reviewerTeam)
+	}
+	for _, teamName := range reviewerTeams(authorTeam) {
+		if teamName != reviewerTeam {
+			teams = append(teams, teamName)
+		}
+	}
+	return teams
+}
+
+// replacementTeams searches the author's team and its fallbacks in order, like
+// the initial assignment, and only then the team the old reviewer belongs to.
+// The reviewer may have come from a fallback team or have no team at all.
+func replacementTeams(reviewerTeam string, authorTeam *domain.Team) []string {
+	teams := reviewerTeams(authorTeam)
+	if reviewerTeam != "" && !contains(teams, reviewerTeam) {
+		teams = append(teams, reviewerTeam)
+	}
+	return teams
+}
@@ -0,0 +1,83 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+	"github.com/nikitaenmi/AvitoTest/internal/repository/memory"
+	"github.com/nikitaenmi/AvitoTest/internal/service"
+)
+
+const (
+	benchTeamSize     = 200
+	benchPRs          = 3000
+	benchDeactivating = 20
+)
+
+// seedDeactivationStore fills a store with one team of benchTeamSize users and
+// benchPRs open pull requests, each reviewed by two teammates of its author.
+func seedDeactivationStore(b *testing.B, teamName string) *memory.Store {
+	b.Helper()
+	ctx := context.Background()
+	store := memory.NewStore()
+	repos := memory.NewRepositories(store)
+
+	err := repos.Teams.Create(ctx, domain.Team{TeamName: teamName, RequiredReviewers: domain.DefaultRequiredReviewers})
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < benchTeamSize; i++ {
+		userID := fmt.Sprintf("u%d", i)
+		err := repos.Users.Create(ctx, domain.User{UserID: userID, Username: userID, TeamName: teamName, IsActive: true})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	for i := 0; i < benchPRs; i++ {
+		prID := fmt.Sprintf("pr-%d", i)
+		err := repos.PRs.Create(ctx, domain.PullRequest{
+			PullRequestID:   prID,
+			PullRequestName: prID,
+			AuthorID:        fmt.Sprintf("u%d", i%benchTeamSize),
+			Status:          domain.PRStatusOpen,
+			AssignedReviewers: []string{
+				fmt.Sprintf("u%d", (i+1)%benchTeamSize),
+				fmt.Sprintf("u%d", (i+2)%benchTeamSize),
+			},
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	return store
+}
+
+func BenchmarkDeactivateTeamUsers(b *testing.B) {
+	ctx := context.Background()
+	teamName := "backend"
+	userIDs := make([]string, benchDeactivating)
+	for i := range userIDs {
+		userIDs[i] = fmt.Sprintf("u%d", i)
+	}
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		store := seedDeactivationStore(b, teamName)
+		svc := service.NewService(
+			memory.NewRepositories(store), memory.NewTxManager(store),
+			service.NewLeastLoadedSelector(), service.NewTeamPolicy(), nil,
+		)
+		b.StartTimer()
+
+		report, err := svc.DeactivateTeamUsers(ctx, domain.TeamFilter{TeamName: &teamName}, userIDs)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(report.Reassigned) == 0 {
+			b.Fatal("expected reviews to be reassigned")
+		}
+	}
+}
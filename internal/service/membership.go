@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+func (s *Service) AddTeamMembers(
+	ctx context.Context, filter domain.TeamFilter, members []domain.User,
+) (*domain.Team, error) {
+	if filter.TeamName == nil || *filter.TeamName == "" {
+		return nil, domain.NewValidationError("team name cannot be empty")
+	}
+	if len(members) == 0 {
+		return nil, domain.NewValidationError("members cannot be empty")
+	}
+	if err := validateMembers(members); err != nil {
+		return nil, err
+	}
+
+	var team *domain.Team
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		exists, err := repos.Teams.Exists(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return domain.NewNotFoundError("team")
+		}
+
+		if err := s.addMembers(ctx, repos, *filter.TeamName, members); err != nil {
+			return err
+		}
+
+		team, err = repos.Teams.FindOne(ctx, filter)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
+// RemoveTeamMember detaches a user from their team and hands their open reviews
+// to other reviewers. The user row is kept, inactive and without a team, so the
+// pull requests and reviews that reference it stay intact; the user can later
+// be added to any team again.
+func (s *Service) RemoveTeamMember(
+	ctx context.Context, filter domain.TeamFilter, userID string,
+) (*domain.DeactivationReport, error) {
+	if filter.TeamName == nil || *filter.TeamName == "" {
+		return nil, domain.NewValidationError("team name cannot be empty")
+	}
+	if userID == "" {
+		return nil, domain.NewValidationError("user ID cannot be empty")
+	}
+
+	var report *domain.DeactivationReport
+	err := s.withinTxRetry(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		report = newDeactivationReport()
+
+		exists, err := repos.Teams.Exists(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return domain.NewNotFoundError("team")
+		}
+
+		user, err := repos.Users.FindOne(ctx, domain.UserFilter{UserID: &userID, TeamName: filter.TeamName})
+		if err != nil {
+			return domain.NewNotFoundError("user " + userID + " in team")
+		}
+
+		if err := ensureNoOpenAuthoredPRs(ctx, repos, userID); err != nil {
+			return err
+		}
+
+		removed := *user
+		removed.TeamName = ""
+		removed.IsActive = false
+		removed.Role = domain.UserRoleMember
+		if err := repos.Users.Update(ctx, &removed); err != nil {
+			return err
+		}
+
+		return s.releaseReviewers(ctx, repos, []domain.User{*user}, releaseLeftTeam, report)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordReleased(report)
+	return report, nil
+}
+
+// MoveUserToTeam moves a user into another team as a MEMBER, optionally renaming
+// them, and replaces them on the open pull requests they were reviewing for the
+// old team. Reviews on pull requests authored outside the old team are kept.
+func (s *Service) MoveUserToTeam(
+	ctx context.Context, filter domain.UserFilter, teamName, username string,
+) (*domain.User, *domain.DeactivationReport, error) {
+	if filter.UserID == nil || *filter.UserID == "" {
+		return nil, nil, domain.NewValidationError("user ID cannot be empty")
+	}
+	if teamName == "" {
+		return nil, nil, domain.NewValidationError("team name cannot be empty")
+	}
+
+	var moved domain.User
+	var report *domain.DeactivationReport
+	err := s.withinTxRetry(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		report = newDeactivationReport()
+
+		user, err := repos.Users.FindOne(ctx, filter)
+		if err != nil {
+			return domain.NewNotFoundError("user")
+		}
+		if user.TeamName == teamName {
+			return domain.NewValidationError("user " + user.UserID + " is already in team " + teamName)
+		}
+
+		exists, err := repos.Teams.Exists(ctx, domain.TeamFilter{TeamName: &teamName})
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return domain.NewNotFoundError("team")
+		}
+
+		// Leads lead their own team only.
+		moved = *user
+		moved.TeamName = teamName
+		moved.Role = domain.UserRoleMember
+		if username != "" {
+			moved.Username = username
+		}
+		if err := repos.Users.Update(ctx, &moved); err != nil {
+			return err
+		}
+
+		if user.TeamName == "" || !user.IsActive {
+			return nil
+		}
+		return s.releaseReviewers(ctx, repos, []domain.User{*user}, releaseMovedTeam, report)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.recordReleased(report)
+	return &moved, report, nil
+}
+
+func ensureNoOpenAuthoredPRs(ctx context.Context, repos domain.Repositories, userID string) error {
+	for _, status := range []string{domain.PRStatusOpen, domain.PRStatusDraft} {
+		authored, err := repos.PRs.Exists(ctx, domain.PRFilter{AuthorID: &userID, Status: &status})
+		if err != nil {
+			return err
+		}
+		if authored {
+			return domain.NewValidationError("user " + userID + " still authors open pull requests")
+		}
+	}
+	return nil
+}
+
+// validateMembers checks the members of a request and defaults their role to
+// MEMBER.
+func validateMembers(members []domain.User) error {
+	seen := make(map[string]bool, len(members))
+	for i, member := range members {
+		if member.UserID == "" {
+			return domain.NewValidationError("user ID cannot be empty")
+		}
+		if seen[member.UserID] {
+			return domain.NewValidationError("user " + member.UserID + " is listed twice")
+		}
+		seen[member.UserID] = true
+
+		if member.Role == "" {
+			members[i].Role = domain.UserRoleMember
+		}
+		if err := validateUserRole(members[i].Role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateUserRole(role string) error {
+	if role != domain.UserRoleMember && role != domain.UserRoleLead {
+		return domain.NewValidationError(
+			"role must be " + domain.UserRoleMember + " or " + domain.UserRoleLead)
+	}
+	return nil
+}
+
+// addMembers puts members into teamName. Users that were removed from their
+// previous team join with the given username and activity; users that still
+// belong to a team are rejected.
+func (s *Service) addMembers(
+	ctx context.Context, repos domain.Repositories, teamName string, members []domain.User,
+) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	userIDs := make([]string, len(members))
+	for i, member := range members {
+		userIDs[i] = member.UserID
+	}
+
+	existing, err := repos.Users.FindAll(ctx, domain.UserFilter{UserIDs: userIDs})
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(existing))
+	for _, user := range existing {
+		if user.TeamName == teamName {
+			return domain.NewValidationError("user " + user.UserID + " is already in team " + teamName)
+		}
+		if user.TeamName != "" {
+			return domain.NewUserInOtherTeamError(user.UserID, user.TeamName)
+		}
+		known[user.UserID] = true
+	}
+
+	for _, member := range members {
+		member.TeamName = teamName
+		if known[member.UserID] {
+			if err := repos.Users.Update(ctx, &member); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := repos.Users.Create(ctx, member); err != nil {
+			if errors.Is(err, domain.ErrDuplicateKey) {
+				return domain.NewUserInOtherTeamError(member.UserID, "")
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+var prTransitions = map[string][]string{
+	domain.PRStatusDraft:  {domain.PRStatusOpen, domain.PRStatusClosed},
+	domain.PRStatusOpen:   {domain.PRStatusMerged, domain.PRStatusClosed},
+	domain.PRStatusClosed: {domain.PRStatusOpen},
+	domain.PRStatusMerged: {},
+}
+
+func checkTransition(from, to string) error {
+	for _, allowed := range prTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return domain.NewInvalidTransitionError(from, to)
+}
+
+func (s *Service) ClosePR(ctx context.Context, filter domain.PRFilter) (*domain.PullRequest, error) {
+	var pr *domain.PullRequest
+	err := s.withinTxRetry(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		var err error
+		pr, err = s.findPRForTransition(ctx, repos, filter, domain.PRActionClose, domain.PRStatusClosed)
+		if err != nil {
+			return err
+		}
+
+		return s.closePR(ctx, repos, pr, "pull request closed")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pr, nil
+}
+
+func (s *Service) closePR(ctx context.Context, repos domain.Repositories, pr *domain.PullRequest, reason string) error {
+	released := pr.AssignedReviewers
+
+	now := time.Now()
+	pr.Status = domain.PRStatusClosed
+	pr.AssignedReviewers = []string{}
+	pr.FallbackReviewers = nil
+	pr.ClosedAt = &now
+
+	if err := repos.PRs.Update(ctx, pr); err != nil {
+		return err
+	}
+
+	assignments := newAssignments(pr, domain.AssignmentActionUnassigned, domain.AssignmentOperationManual,
+		reason, released)
+	return repos.Assignments.Create(ctx, assignments)
+}
+
+func (s *Service) ReopenPR(ctx context.Context, filter domain.PRFilter) (*domain.PullRequest, error) {
+	return s.openPR(ctx, filter, domain.PRActionReopen, domain.PRStatusClosed, "pull request reopened")
+}
+
+func (s *Service) MarkPRReady(ctx context.Context, filter domain.PRFilter) (*domain.PullRequest, error) {
+	return s.openPR(ctx, filter, domain.PRActionReady, domain.PRStatusDraft, "marked ready for review")
+}
+
+func (s *Service) openPR(
+	ctx context.Context, filter domain.PRFilter, action, from, reason string,
+) (*domain.PullRequest, error) {
+	var pr *domain.PullRequest
+	var requiredReviewers int
+	err := s.withinTxRetry(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		var err error
+		pr, err = s.findPRForTransition(ctx, repos, filter, action, domain.PRStatusOpen)
+		if err != nil {
+			return err
+		}
+		if pr.Status != from {
+			return domain.NewInvalidTransitionError(pr.Status, domain.PRStatusOpen)
+		}
+
+		team, err := s.authorTeam(ctx, repos, pr.AuthorID)
+		if err != nil {
+			return err
+		}
+
+		pr.Status = domain.PRStatusOpen
+		pr.ClosedAt = nil
+		if err := s.assignReviewers(ctx, repos, pr, team); err != nil {
+			return err
+		}
+		requiredReviewers = team.RequiredReviewers
+
+		if err := repos.PRs.Update(ctx, pr); err != nil {
+			return err
+		}
+
+		assignments := newAssignments(pr, domain.AssignmentActionAssigned, domain.AssignmentOperationCreate,
+			reason, pr.AssignedReviewers)
+		return repos.Assignments.Create(ctx, assignments)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.PROpened(len(pr.AssignedReviewers), requiredReviewers)
+	return pr, nil
+}
+
+func (s *Service) findPRForTransition(
+	ctx context.Context, repos domain.Repositories, filter domain.PRFilter, action, to string,
+) (*domain.PullRequest, error) {
+	if filter.PullRequestID == nil || *filter.PullRequestID == "" {
+		return nil, domain.NewValidationError("pull request ID cannot be empty")
+	}
+
+	pr, err := repos.PRs.FindOne(ctx, filter)
+	if err != nil {
+		return nil, domain.NewNotFoundError("pull request")
+	}
+	if err := s.authorize(ctx, repos, action, *pr); err != nil {
+		return nil, err
+	}
+
+	if err := checkTransition(pr.Status, to); err != nil {
+		return nil, err
+	}
+
+	return pr, nil
+}
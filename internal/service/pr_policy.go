@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+// TeamPolicy lets the author of a pull request, its assigned reviewers and the
+// leads of the author's team merge, reassign, close, reopen, mark ready or top
+// up its reviewers. Admin tokens are trusted.
+type TeamPolicy struct{}
+
+func NewTeamPolicy() *TeamPolicy {
+	return &TeamPolicy{}
+}
+
+func (p *TeamPolicy) Authorize(
+	ctx context.Context, repos domain.Repositories, caller domain.Caller, action string, pr domain.PullRequest,
+) error {
+	if caller.Role == domain.RoleAdmin {
+		return nil
+	}
+	if caller.UserID != "" {
+		if caller.UserID == pr.AuthorID || contains(pr.AssignedReviewers, caller.UserID) {
+			return nil
+		}
+
+		users, err := repos.Users.FindAll(ctx, domain.UserFilter{UserIDs: []string{caller.UserID, pr.AuthorID}})
+		if err != nil {
+			return err
+		}
+		var callerUser, author *domain.User
+		for i := range users {
+			switch users[i].UserID {
+			case caller.UserID:
+				callerUser = &users[i]
+			case pr.AuthorID:
+				author = &users[i]
+			}
+		}
+		if callerUser != nil && author != nil && callerUser.Role == domain.UserRoleLead &&
+			callerUser.TeamName != "" && callerUser.TeamName == author.TeamName {
+			return nil
+		}
+	}
+
+	return domain.NewForbiddenError(
+		"only the author, an assigned reviewer or a team lead can " + action + " this pull request")
+}
+
+// authorize consults the policy for requests made on behalf of a caller.
+// Calls without a caller come from inside the process and are not checked.
+func (s *Service) authorize(ctx context.Context, repos domain.Repositories, action string, pr domain.PullRequest) error {
+	caller, ok := domain.CallerFromContext(ctx)
+	if !ok || s.policy == nil {
+		return nil
+	}
+	return s.policy.Authorize(ctx, repos, caller, action, pr)
+}
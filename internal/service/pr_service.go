@@ -2,7 +2,7 @@ package service
 
 import (
 	"context"
-	"math/rand"
+	"errors"
 	"time"
 
 	"github.com/nikitaenmi/AvitoTest/internal/domain"
@@ -12,40 +12,66 @@ func (s *Service) CreatePR(ctx context.Context, pr domain.PullRequest) (*domain.
 	if pr.PullRequestID == "" {
 		return nil, domain.NewValidationError("pull request ID cannot be empty")
 	}
-
-	exists, err := s.prRepo.Exists(ctx, domain.PRFilter{PullRequestID: &pr.PullRequestID})
-	if err != nil {
-		return nil, err
-	}
-	if exists {
-		return nil, domain.NewPRExistsError()
+	if pr.Status == "" {
+		pr.Status = domain.PRStatusOpen
 	}
-
-	author, err := s.userRepo.FindOne(ctx, domain.UserFilter{UserID: &pr.AuthorID})
-	if err != nil {
-		return nil, domain.NewNotFoundError("author")
+	if pr.Status != domain.PRStatusOpen && pr.Status != domain.PRStatusDraft {
+		return nil, domain.NewValidationError("pull request can only be created as OPEN or DRAFT")
 	}
-
-	teamMembers, err := s.GetActiveTeamMembers(ctx, author.TeamName, pr.AuthorID)
-	if err != nil {
+	if err := actAs(ctx, pr.AuthorID, "create pull requests"); err != nil {
 		return nil, err
 	}
 
-	reviewers := []string{}
-	for i := 0; i < len(teamMembers) && i < 2; i++ {
-		reviewers = append(reviewers, teamMembers[i].UserID)
-	}
+	var requiredReviewers int
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		exists, err := repos.PRs.Exists(ctx, domain.PRFilter{PullRequestID: &pr.PullRequestID})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return domain.NewPRExistsError()
+		}
 
-	now := time.Now()
-	pr.Status = domain.PRStatusOpen
-	pr.AssignedReviewers = reviewers
-	pr.CreatedAt = &now
-	pr.MergedAt = nil
+		author, err := repos.Users.FindOne(ctx, domain.UserFilter{UserID: &pr.AuthorID})
+		if err != nil {
+			return domain.NewNotFoundError("author")
+		}
+
+		now := time.Now()
+		pr.AssignedReviewers = []string{}
+		pr.FallbackReviewers = nil
+		pr.CreatedAt = &now
+		pr.MergedAt = nil
+		pr.ClosedAt = nil
 
-	if err := s.prRepo.Create(ctx, pr); err != nil {
+		if pr.Status == domain.PRStatusOpen {
+			team, err := repos.Teams.FindOne(ctx, domain.TeamFilter{TeamName: &author.TeamName})
+			if err != nil {
+				return domain.NewNotFoundError("team")
+			}
+
+			if err := s.assignReviewers(ctx, repos, &pr, team); err != nil {
+				return err
+			}
+			requiredReviewers = team.RequiredReviewers
+		}
+
+		if err := repos.PRs.Create(ctx, pr); err != nil {
+			if errors.Is(err, domain.ErrDuplicateKey) {
+				return domain.NewPRExistsError()
+			}
+			return err
+		}
+
+		assignments := newAssignments(&pr, domain.AssignmentActionAssigned, domain.AssignmentOperationCreate,
+			"pull request created", pr.AssignedReviewers)
+		return repos.Assignments.Create(ctx, assignments)
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	s.events.PRCreated(len(pr.AssignedReviewers), requiredReviewers)
 	return &pr, nil
 }
 
@@ -54,96 +80,318 @@ func (s *Service) MergePR(ctx context.Context, filter domain.PRFilter) error {
 		return domain.NewValidationError("pull request ID cannot be empty")
 	}
 
-	pr, err := s.prRepo.FindOne(ctx, filter)
+	var merged bool
+	err := s.withinTxRetry(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		merged = false
+		pr, err := repos.PRs.FindOne(ctx, filter)
+		if err != nil {
+			return domain.NewNotFoundError("pull request")
+		}
+		if err := s.authorize(ctx, repos, domain.PRActionMerge, *pr); err != nil {
+			return err
+		}
+
+		if pr.Status == domain.PRStatusMerged {
+			return nil
+		}
+		if err := checkTransition(pr.Status, domain.PRStatusMerged); err != nil {
+			return err
+		}
+
+		team, err := s.authorTeam(ctx, repos, pr.AuthorID)
+		if err != nil {
+			return err
+		}
+
+		if team.RequiredApprovals > 0 {
+			approvals, err := s.countApprovals(ctx, repos, *pr)
+			if err != nil {
+				return err
+			}
+			if approvals < team.RequiredApprovals {
+				return domain.NewNotEnoughApprovalsError(approvals, team.RequiredApprovals)
+			}
+		}
+
+		pr.Status = domain.PRStatusMerged
+		now := time.Now()
+		pr.MergedAt = &now
+		if caller, ok := domain.CallerFromContext(ctx); ok {
+			pr.MergedBy = caller.Identity()
+		}
+
+		merged = true
+		return repos.PRs.Update(ctx, pr)
+	})
 	if err != nil {
-		return domain.NewNotFoundError("pull request")
+		return err
 	}
 
-	if pr.Status == domain.PRStatusMerged {
-		return nil
+	if merged {
+		s.events.PRMerged()
 	}
+	return nil
+}
 
-	pr.Status = domain.PRStatusMerged
-	now := time.Now()
-	pr.MergedAt = &now
+func (s *Service) TopUpReviewers(ctx context.Context, filter domain.PRFilter) (*domain.PullRequest, error) {
+	if filter.PullRequestID == nil || *filter.PullRequestID == "" {
+		return nil, domain.NewValidationError("pull request ID cannot be empty")
+	}
 
-	return s.prRepo.Update(ctx, pr)
+	var pr *domain.PullRequest
+	err := s.withinTxRetry(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		var err error
+		pr, err = repos.PRs.FindOne(ctx, filter)
+		if err != nil {
+			return domain.NewNotFoundError("pull request")
+		}
+		if err := s.authorize(ctx, repos, domain.PRActionTopUp, *pr); err != nil {
+			return err
+		}
+
+		if pr.Status == domain.PRStatusMerged {
+			return domain.NewPRMergedError()
+		}
+		if pr.Status != domain.PRStatusOpen {
+			return domain.NewPRNotOpenError()
+		}
+
+		team, err := s.authorTeam(ctx, repos, pr.AuthorID)
+		if err != nil {
+			return err
+		}
+
+		missing := team.RequiredReviewers - len(pr.AssignedReviewers)
+		if missing <= 0 {
+			return nil
+		}
+
+		excludedUsers := append([]string{pr.AuthorID}, pr.AssignedReviewers...)
+		reviewers, fallbackReviewers, err := s.pickReviewers(
+			ctx, repos, team.TeamName, reviewerTeams(team), excludedUsers, missing,
+		)
+		if err != nil {
+			return err
+		}
+		if len(reviewers) == 0 {
+			return nil
+		}
+
+		pr.AssignedReviewers = append(pr.AssignedReviewers, reviewers...)
+		pr.FallbackReviewers = append(pr.FallbackReviewers, fallbackReviewers...)
+
+		if err := repos.PRs.Update(ctx, pr); err != nil {
+			return err
+		}
+
+		assignments := newAssignments(pr, domain.AssignmentActionAssigned, domain.AssignmentOperationManual,
+			"topped up to required reviewers", reviewers)
+		return repos.Assignments.Create(ctx, assignments)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pr, nil
 }
 
-func (s *Service) ReassignReviewer(ctx context.Context, filter domain.PRFilter, oldReviewerID string) (string, error) {
+func (s *Service) ReassignReviewer(
+	ctx context.Context, filter domain.PRFilter, oldReviewerID, reason string,
+) (string, error) {
 	if filter.PullRequestID == nil || *filter.PullRequestID == "" {
 		return "", domain.NewValidationError("pull request ID cannot be empty")
 	}
 	if oldReviewerID == "" {
 		return "", domain.NewValidationError("old reviewer ID cannot be empty")
 	}
+	if reason == "" {
+		reason = "reassigned on request"
+	}
 
-	pr, err := s.prRepo.FindOne(ctx, filter)
+	var newReviewerID string
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		pr, err := repos.PRs.FindOne(ctx, filter)
+		if err != nil {
+			return domain.NewNotFoundError("pull request")
+		}
+		if err := s.authorize(ctx, repos, domain.PRActionReassign, *pr); err != nil {
+			return err
+		}
+
+		if pr.Status == domain.PRStatusMerged {
+			return domain.NewPRMergedError()
+		}
+		if pr.Status != domain.PRStatusOpen {
+			return domain.NewPRNotOpenError()
+		}
+
+		reviewerIndex := -1
+		for i, reviewer := range pr.AssignedReviewers {
+			if reviewer == oldReviewerID {
+				reviewerIndex = i
+				break
+			}
+		}
+
+		if reviewerIndex == -1 {
+			return domain.NewNotAssignedError()
+		}
+
+		oldReviewer, err := repos.Users.FindOne(ctx, domain.UserFilter{UserID: &oldReviewerID})
+		if err != nil {
+			return domain.NewNotFoundError("old reviewer")
+		}
+
+		authorTeam, err := s.authorTeam(ctx, repos, pr.AuthorID)
+		if err != nil {
+			return err
+		}
+
+		excludedUsers := append([]string{pr.AuthorID}, pr.AssignedReviewers...)
+
+		reviewers, fallbackReviewers, err := s.pickReviewers(
+			ctx, repos, authorTeam.TeamName, replacementTeams(oldReviewer.TeamName, authorTeam), excludedUsers, 1,
+		)
+		if err != nil {
+			return err
+		}
+
+		if len(reviewers) == 0 {
+			return domain.NewNoCandidateError()
+		}
+
+		newReviewerID = reviewers[0]
+
+		pr.AssignedReviewers[reviewerIndex] = newReviewerID
+		pr.FallbackReviewers = append(without(pr.FallbackReviewers, oldReviewerID), fallbackReviewers...)
+
+		if err := repos.PRs.Update(ctx, pr); err != nil {
+			return err
+		}
+
+		assignments := append(
+			newAssignments(pr, domain.AssignmentActionUnassigned, domain.AssignmentOperationReassign,
+				reason, []string{oldReviewerID}),
+			newAssignments(pr, domain.AssignmentActionAssigned, domain.AssignmentOperationReassign,
+				reason, []string{newReviewerID})...,
+		)
+		return repos.Assignments.Create(ctx, assignments)
+	})
 	if err != nil {
-		return "", domain.NewNotFoundError("pull request")
+		var domainErr *domain.DomainError
+		if errors.As(err, &domainErr) && domainErr.Type == domain.ErrorTypeNoCandidate {
+			s.events.NoCandidate()
+		}
+		return "", conflictError(err)
 	}
 
-	if pr.Status == domain.PRStatusMerged {
-		return "", domain.NewPRMergedError()
-	}
+	s.events.ReviewersReassigned(1)
+	return newReviewerID, nil
+}
 
-	found := false
-	reviewerIndex := -1
-	for i, reviewer := range pr.AssignedReviewers {
-		if reviewer == oldReviewerID {
-			found = true
-			reviewerIndex = i
-			break
-		}
+func (s *Service) authorTeam(ctx context.Context, repos domain.Repositories, authorID string) (*domain.Team, error) {
+	author, err := repos.Users.FindOne(ctx, domain.UserFilter{UserID: &authorID})
+	if err != nil {
+		return nil, domain.NewNotFoundError("author")
 	}
 
-	if !found {
-		return "", domain.NewNotAssignedError()
+	// Pull requests left open by an archived team still need its settings.
+	team, err := repos.Teams.FindOne(ctx, domain.TeamFilter{TeamName: &author.TeamName, IncludeArchived: true})
+	if err != nil {
+		return nil, domain.NewNotFoundError("team")
 	}
+	return team, nil
+}
 
-	oldReviewer, err := s.userRepo.FindOne(ctx, domain.UserFilter{UserID: &oldReviewerID})
+func (s *Service) assignReviewers(
+	ctx context.Context, repos domain.Repositories, pr *domain.PullRequest, team *domain.Team,
+) error {
+	reviewers, fallbackReviewers, err := s.pickReviewers(
+		ctx, repos, team.TeamName, reviewerTeams(team), []string{pr.AuthorID}, team.RequiredReviewers,
+	)
 	if err != nil {
-		return "", domain.NewNotFoundError("old reviewer")
+		return err
 	}
 
-	excludedUsers := []string{oldReviewerID, pr.AuthorID}
-	for _, reviewer := range pr.AssignedReviewers {
-		if reviewer != oldReviewerID {
-			excludedUsers = append(excludedUsers, reviewer)
+	pr.AssignedReviewers = reviewers
+	pr.FallbackReviewers = fallbackReviewers
+	return nil
+}
+
+func (s *Service) pickReviewers(
+	ctx context.Context, repos domain.Repositories,
+	authorTeam string, teamNames []string, excludeUserIDs []string, count int,
+) ([]string, []string, error) {
+	return s.newReviewerPlanner(repos).pick(ctx, authorTeam, teamNames, excludeUserIDs, count)
+}
+
+func reviewerTeams(team *domain.Team) []string {
+	return append([]string{team.TeamName}, team.FallbackTeams...)
+}
+
+func without(ids []string, id string) []string {
+	result := make([]string, 0, len(ids))
+	for _, existing := range ids {
+		if existing != id {
+			result = append(result, existing)
 		}
 	}
+	return result
+}
 
-	availableReviewers, err := s.GetActiveTeamMembers(ctx, oldReviewer.TeamName, excludedUsers...)
+func (s *Service) GetPR(ctx context.Context, filter domain.PRFilter) (*domain.PullRequest, error) {
+	if filter.PullRequestID == nil || *filter.PullRequestID == "" {
+		return nil, domain.NewValidationError("pull request ID cannot be empty")
+	}
+
+	pr, err := s.repos.PRs.FindOne(ctx, filter)
 	if err != nil {
-		return "", err
+		return nil, domain.NewNotFoundError("pull request")
 	}
 
-	if len(availableReviewers) == 0 {
-		return "", domain.NewNoCandidateError()
+	prs := []domain.PullRequest{*pr}
+	if err := s.attachReviewStates(ctx, s.repos, prs); err != nil {
+		return nil, err
 	}
+	return &prs[0], nil
+}
 
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	newReviewer := availableReviewers[r.Intn(len(availableReviewers))]
-	newReviewerID := newReviewer.UserID
+func (s *Service) ListPRs(ctx context.Context, filter domain.PRFilter) ([]domain.PullRequest, *domain.PRCursor, error) {
+	if filter.Status != nil && !isValidPRStatus(*filter.Status) {
+		return nil, nil, domain.NewValidationError("unknown pull request status " + *filter.Status)
+	}
+	if filter.Limit < 0 || filter.Limit > domain.MaxPRPageSize {
+		return nil, nil, domain.NewValidationError("limit must be between 1 and 100")
+	}
+	if filter.Limit == 0 {
+		filter.Limit = domain.DefaultPRPageSize
+	}
 
-	pr.AssignedReviewers[reviewerIndex] = newReviewerID
+	pageSize := filter.Limit
+	filter.Limit = pageSize + 1
 
-	if err := s.prRepo.Update(ctx, pr); err != nil {
-		return "", err
+	prs, err := s.repos.PRs.FindAll(ctx, filter)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return newReviewerID, nil
-}
+	var next *domain.PRCursor
+	if len(prs) > pageSize {
+		prs = prs[:pageSize]
+		last := prs[pageSize-1]
+		next = &domain.PRCursor{PullRequestID: last.PullRequestID}
+		if last.CreatedAt != nil {
+			next.CreatedAt = *last.CreatedAt
+		}
+	}
 
-func (s *Service) GetPR(ctx context.Context, filter domain.PRFilter) (*domain.PullRequest, error) {
-	if filter.PullRequestID == nil || *filter.PullRequestID == "" {
-		return nil, domain.NewValidationError("pull request ID cannot be empty")
+	if err := s.attachReviewStates(ctx, s.repos, prs); err != nil {
+		return nil, nil, err
 	}
-	return s.prRepo.FindOne(ctx, filter)
+	return prs, next, nil
 }
 
-func (s *Service) HealthCheck(ctx context.Context) error {
-	testTeam := "health_check_test_team_12345"
-	_, err := s.teamRepo.Exists(ctx, domain.TeamFilter{TeamName: &testTeam})
-	return err
+func isValidPRStatus(status string) bool {
+	_, ok := prTransitions[status]
+	return ok
 }
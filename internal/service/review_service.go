@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+func (s *Service) SubmitReview(ctx context.Context, review domain.Review) (*domain.PullRequest, error) {
+	if review.PullRequestID == "" {
+		return nil, domain.NewValidationError("pull request ID cannot be empty")
+	}
+	if review.ReviewerID == "" {
+		return nil, domain.NewValidationError("reviewer ID cannot be empty")
+	}
+	if !isValidReviewState(review.State) {
+		return nil, domain.NewValidationError("state must be one of APPROVED, CHANGES_REQUESTED, COMMENTED")
+	}
+	if err := actAs(ctx, review.ReviewerID, "submit reviews"); err != nil {
+		return nil, err
+	}
+
+	var pr *domain.PullRequest
+	err := s.withinTxRetry(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		var err error
+		pr, err = repos.PRs.FindOne(ctx, domain.PRFilter{PullRequestID: &review.PullRequestID})
+		if err != nil {
+			return domain.NewNotFoundError("pull request")
+		}
+
+		if pr.Status != domain.PRStatusOpen {
+			return domain.NewPRNotOpenError()
+		}
+
+		if !contains(pr.AssignedReviewers, review.ReviewerID) {
+			return domain.NewNotAssignedError()
+		}
+
+		review.SubmittedAt = time.Now()
+		if err := repos.Reviews.Create(ctx, review); err != nil {
+			return err
+		}
+
+		// Bump the version so that a concurrent merge, close or reassignment
+		// conflicts with the review instead of missing it.
+		if err := repos.PRs.Update(ctx, pr); err != nil {
+			return err
+		}
+
+		prs := []domain.PullRequest{*pr}
+		if err := s.attachReviewStates(ctx, repos, prs); err != nil {
+			return err
+		}
+		pr = &prs[0]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// attachReviewStates sets the state of every assigned reviewer from their
+// latest review. Reviews submitted before the reviewer's latest assignment,
+// e.g. before the pull request was closed and reopened, no longer count.
+func (s *Service) attachReviewStates(
+	ctx context.Context, repos domain.Repositories, prs []domain.PullRequest,
+) error {
+	if len(prs) == 0 {
+		return nil
+	}
+
+	prIDs := make([]string, len(prs))
+	for i, pr := range prs {
+		prIDs[i] = pr.PullRequestID
+	}
+
+	reviews, err := repos.Reviews.FindLatest(ctx, prIDs)
+	if err != nil {
+		return err
+	}
+
+	assignments, err := repos.Assignments.FindLatestAssigned(ctx, prIDs)
+	if err != nil {
+		return err
+	}
+
+	assignedAt := make(map[string]map[string]time.Time, len(prs))
+	for _, assignment := range assignments {
+		if assignedAt[assignment.PullRequestID] == nil {
+			assignedAt[assignment.PullRequestID] = make(map[string]time.Time)
+		}
+		assignedAt[assignment.PullRequestID][assignment.UserID] = assignment.CreatedAt
+	}
+
+	latest := make(map[string]map[string]domain.Review, len(prs))
+	for _, review := range reviews {
+		if since, ok := assignedAt[review.PullRequestID][review.ReviewerID]; ok && review.SubmittedAt.Before(since) {
+			continue
+		}
+		if latest[review.PullRequestID] == nil {
+			latest[review.PullRequestID] = make(map[string]domain.Review)
+		}
+		latest[review.PullRequestID][review.ReviewerID] = review
+	}
+
+	for i := range prs {
+		states := make([]domain.ReviewerState, 0, len(prs[i].AssignedReviewers))
+		for _, reviewerID := range prs[i].AssignedReviewers {
+			state := domain.ReviewerState{ReviewerID: reviewerID, State: domain.ReviewStatePending}
+			if review, ok := latest[prs[i].PullRequestID][reviewerID]; ok {
+				submittedAt := review.SubmittedAt
+				state.State = review.State
+				state.SubmittedAt = &submittedAt
+			}
+			states = append(states, state)
+		}
+		prs[i].Reviews = states
+	}
+
+	return nil
+}
+
+func (s *Service) countApprovals(
+	ctx context.Context, repos domain.Repositories, pr domain.PullRequest,
+) (int, error) {
+	prs := []domain.PullRequest{pr}
+	if err := s.attachReviewStates(ctx, repos, prs); err != nil {
+		return 0, err
+	}
+
+	approvals := 0
+	for _, state := range prs[0].Reviews {
+		if state.State == domain.ReviewStateApproved {
+			approvals++
+		}
+	}
+	return approvals, nil
+}
+
+func isValidReviewState(state string) bool {
+	switch state {
+	case domain.ReviewStateApproved, domain.ReviewStateChangesRequested, domain.ReviewStateCommented:
+		return true
+	default:
+		return false
+	}
+}
+
+func contains(ids []string, id string) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
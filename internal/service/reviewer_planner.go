@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+// reviewerPlanner caches active team members and their open review counts so
+// that many assignments can be planned without re-querying per pull request.
+type reviewerPlanner struct {
+	service *Service
+	repos   domain.Repositories
+	pools   map[string][]domain.User
+	loads   map[string]int
+}
+
+func (s *Service) newReviewerPlanner(repos domain.Repositories) *reviewerPlanner {
+	return &reviewerPlanner{
+		service: s,
+		repos:   repos,
+		pools:   make(map[string][]domain.User),
+		loads:   make(map[string]int),
+	}
+}
+
+func (p *reviewerPlanner) pick(
+	ctx context.Context, authorTeam string, teamNames []string, excludeUserIDs []string, count int,
+) ([]string, []string, error) {
+	reviewers := []string{}
+	var fallbackReviewers []string
+
+	excluded := make(map[string]bool, len(excludeUserIDs)+count)
+	for _, userID := range excludeUserIDs {
+		excluded[userID] = true
+	}
+
+	for _, teamName := range teamNames {
+		if len(reviewers) >= count {
+			break
+		}
+
+		pool, err := p.pool(ctx, teamName)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		candidates := make([]domain.ReviewerCandidate, 0, len(pool))
+		for _, member := range pool {
+			if !excluded[member.UserID] {
+				candidates = append(candidates, domain.ReviewerCandidate{
+					User:        member,
+					OpenReviews: p.loads[member.UserID],
+				})
+			}
+		}
+
+		for _, reviewer := range p.service.selector.Select(candidates, count-len(reviewers)) {
+			reviewers = append(reviewers, reviewer.UserID)
+			excluded[reviewer.UserID] = true
+			p.loads[reviewer.UserID]++
+			if teamName != authorTeam {
+				fallbackReviewers = append(fallbackReviewers, reviewer.UserID)
+			}
+		}
+	}
+
+	return reviewers, fallbackReviewers, nil
+}
+
+func (p *reviewerPlanner) pool(ctx context.Context, teamName string) ([]domain.User, error) {
+	if pool, ok := p.pools[teamName]; ok {
+		return pool, nil
+	}
+
+	members, err := p.service.activeTeamMembers(ctx, p.repos, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]string, len(members))
+	for i, member := range members {
+		userIDs[i] = member.UserID
+	}
+
+	if len(userIDs) > 0 {
+		openReviews, err := p.repos.PRs.CountOpenReviews(ctx, userIDs)
+		if err != nil {
+			return nil, err
+		}
+		for userID, openReviewCount := range openReviews {
+			p.loads[userID] = openReviewCount
+		}
+	}
+
+	p.pools[teamName] = members
+	return members, nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+// LeastLoadedSelector picks the candidates with the fewest open reviews,
+// breaking ties randomly.
+type LeastLoadedSelector struct{}
+
+func NewLeastLoadedSelector() *LeastLoadedSelector {
+	return &LeastLoadedSelector{}
+}
+
+func (s *LeastLoadedSelector) Select(candidates []domain.ReviewerCandidate, count int) []domain.User {
+	if count <= 0 {
+		return nil
+	}
+
+	shuffled := make([]domain.ReviewerCandidate, len(candidates))
+	copy(shuffled, candidates)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	sort.SliceStable(shuffled, func(i, j int) bool {
+		return shuffled[i].OpenReviews < shuffled[j].OpenReviews
+	})
+
+	if count > len(shuffled) {
+		count = len(shuffled)
+	}
+
+	selected := make([]domain.User, 0, count)
+	for _, candidate := range shuffled[:count] {
+		selected = append(selected, candidate.User)
+	}
+	return selected
+}
@@ -3,17 +3,34 @@ package service
 import "github.com/nikitaenmi/AvitoTest/internal/domain"
 
 type Service struct {
-	userRepo domain.UserRepository
-	teamRepo domain.TeamRepository
-	prRepo   domain.PRRepository
+	repos     domain.Repositories
+	txManager domain.TxManager
+	selector  domain.ReviewerSelector
+	policy    domain.PRPolicy
+	events    domain.EventRecorder
 }
 
+// NewService builds the service. A nil events recorder discards events.
 func NewService(
-	userRepo domain.UserRepository, teamRepo domain.TeamRepository, prRepo domain.PRRepository,
+	repos domain.Repositories, txManager domain.TxManager, selector domain.ReviewerSelector, policy domain.PRPolicy,
+	events domain.EventRecorder,
 ) *Service {
+	if events == nil {
+		events = nopEvents{}
+	}
 	return &Service{
-		userRepo: userRepo,
-		teamRepo: teamRepo,
-		prRepo:   prRepo,
+		repos:     repos,
+		txManager: txManager,
+		selector:  selector,
+		policy:    policy,
+		events:    events,
 	}
 }
+
+type nopEvents struct{}
+
+func (nopEvents) PRCreated(int, int)      {}
+func (nopEvents) PROpened(int, int)       {}
+func (nopEvents) PRMerged()               {}
+func (nopEvents) ReviewersReassigned(int) {}
+func (nopEvents) NoCandidate()            {}
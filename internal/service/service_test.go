@@ -0,0 +1,804 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+	"github.com/nikitaenmi/AvitoTest/internal/repository/memory"
+	"github.com/nikitaenmi/AvitoTest/internal/service"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServiceTestSuite struct {
+	suite.Suite
+	ctx   context.Context
+	repos domain.Repositories
+	svc   *service.Service
+}
+
+func (s *ServiceTestSuite) SetupTest() {
+	store := memory.NewStore()
+	s.ctx = context.Background()
+	s.repos = memory.NewRepositories(store)
+	s.svc = service.NewService(s.repos, memory.NewTxManager(store), service.NewLeastLoadedSelector(), service.NewTeamPolicy(), nil)
+}
+
+func TestServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ServiceTestSuite))
+}
+
+func (s *ServiceTestSuite) createTeam(teamName string, userIDs ...string) *domain.Team {
+	members := make([]domain.User, len(userIDs))
+	for i, userID := range userIDs {
+		members[i] = domain.User{UserID: userID, Username: userID, IsActive: true}
+	}
+
+	team, err := s.svc.CreateTeam(s.ctx, domain.Team{TeamName: teamName, Members: members})
+	s.Require().NoError(err)
+	return team
+}
+
+func (s *ServiceTestSuite) createPR(prID, authorID string) *domain.PullRequest {
+	pr, err := s.svc.CreatePR(s.ctx, domain.PullRequest{
+		PullRequestID:   prID,
+		PullRequestName: prID,
+		AuthorID:        authorID,
+	})
+	s.Require().NoError(err)
+	return pr
+}
+
+func (s *ServiceTestSuite) getPR(prID string) *domain.PullRequest {
+	pr, err := s.svc.GetPR(s.ctx, prFilter(prID))
+	s.Require().NoError(err)
+	return pr
+}
+
+func (s *ServiceTestSuite) assertDomainError(err error, errorType domain.ErrorType) {
+	var domainErr *domain.DomainError
+	s.Require().True(errors.As(err, &domainErr), "expected domain error, got %v", err)
+	s.Equal(errorType, domainErr.Type)
+}
+
+func prFilter(prID string) domain.PRFilter {
+	return domain.PRFilter{PullRequestID: &prID}
+}
+
+func (s *ServiceTestSuite) TestCreateTeamAppliesDefaults() {
+	team := s.createTeam("backend", "u1", "u2")
+
+	s.Equal(domain.DefaultRequiredReviewers, team.RequiredReviewers)
+	s.Len(team.Members, 2)
+	for _, member := range team.Members {
+		s.Equal("backend", member.TeamName)
+	}
+
+	_, err := s.svc.CreateTeam(s.ctx, domain.Team{TeamName: "backend"})
+	s.assertDomainError(err, domain.ErrorTypeTeamExists)
+}
+
+func (s *ServiceTestSuite) TestCreateTeamRollsBackOnMemberFailure() {
+	s.createTeam("backend", "u1")
+
+	_, err := s.svc.CreateTeam(s.ctx, domain.Team{
+		TeamName: "frontend",
+		Members: []domain.User{
+			{UserID: "u2", Username: "u2", IsActive: true},
+			{UserID: "u1", Username: "u1", IsActive: true},
+		},
+	})
+	s.assertDomainError(err, domain.ErrorTypeUserInOtherTeam)
+
+	exists, err := s.repos.Teams.Exists(s.ctx, domain.TeamFilter{TeamName: ptr("frontend")})
+	s.Require().NoError(err)
+	s.False(exists)
+
+	_, err = s.svc.GetUserByID(s.ctx, "u2")
+	s.Error(err)
+}
+
+func (s *ServiceTestSuite) TestCreatePRAssignsActiveTeammates() {
+	s.createTeam("backend", "author", "u1", "u2", "u3")
+	_, err := s.svc.SetUserActive(s.ctx, domain.UserFilter{UserID: ptr("u3")}, false)
+	s.Require().NoError(err)
+
+	pr := s.createPR("pr-1", "author")
+
+	s.Equal(domain.PRStatusOpen, pr.Status)
+	s.ElementsMatch([]string{"u1", "u2"}, pr.AssignedReviewers)
+
+	history, err := s.svc.GetAssignmentHistory(s.ctx, prFilter("pr-1"))
+	s.Require().NoError(err)
+	s.Len(history, 2)
+	for _, entry := range history {
+		s.Equal(domain.AssignmentActionAssigned, entry.Action)
+		s.Equal(domain.AssignmentOperationCreate, entry.Operation)
+	}
+
+	_, err = s.svc.CreatePR(s.ctx, domain.PullRequest{PullRequestID: "pr-1", AuthorID: "author"})
+	s.assertDomainError(err, domain.ErrorTypePRExists)
+}
+
+func (s *ServiceTestSuite) TestReviewLoadIsBalanced() {
+	s.createTeam("backend", "author", "u1", "u2", "u3", "u4")
+
+	for i := 0; i < 4; i++ {
+		s.createPR(fmt.Sprintf("pr-%d", i), "author")
+	}
+
+	for _, userID := range []string{"u1", "u2", "u3", "u4"} {
+		prs, err := s.svc.GetUserReviewPRs(s.ctx, domain.UserFilter{UserID: ptr(userID)})
+		s.Require().NoError(err)
+		s.Len(prs, 2, userID)
+	}
+}
+
+func (s *ServiceTestSuite) TestDraftGetsReviewersWhenReady() {
+	s.createTeam("backend", "author", "u1", "u2")
+
+	pr, err := s.svc.CreatePR(s.ctx, domain.PullRequest{
+		PullRequestID: "pr-1",
+		AuthorID:      "author",
+		Status:        domain.PRStatusDraft,
+	})
+	s.Require().NoError(err)
+	s.Empty(pr.AssignedReviewers)
+
+	pr, err = s.svc.MarkPRReady(s.ctx, prFilter("pr-1"))
+	s.Require().NoError(err)
+	s.Equal(domain.PRStatusOpen, pr.Status)
+	s.ElementsMatch([]string{"u1", "u2"}, pr.AssignedReviewers)
+
+	_, err = s.svc.MarkPRReady(s.ctx, prFilter("pr-1"))
+	s.assertDomainError(err, domain.ErrorTypeInvalidTransition)
+}
+
+func (s *ServiceTestSuite) TestCloseReleasesReviewersAndReopenAssigns() {
+	s.createTeam("backend", "author", "u1", "u2")
+	s.createPR("pr-1", "author")
+
+	pr, err := s.svc.ClosePR(s.ctx, prFilter("pr-1"))
+	s.Require().NoError(err)
+	s.Equal(domain.PRStatusClosed, pr.Status)
+	s.Empty(s.getPR("pr-1").AssignedReviewers)
+
+	pr, err = s.svc.ReopenPR(s.ctx, prFilter("pr-1"))
+	s.Require().NoError(err)
+	s.Equal(domain.PRStatusOpen, pr.Status)
+	s.Nil(pr.ClosedAt)
+	s.Len(pr.AssignedReviewers, 2)
+}
+
+func (s *ServiceTestSuite) TestReassignReviewer() {
+	s.createTeam("backend", "author", "u1", "u2", "u3")
+	pr := s.createPR("pr-1", "author")
+
+	oldReviewer := pr.AssignedReviewers[0]
+	newReviewer, err := s.svc.ReassignReviewer(s.ctx, prFilter("pr-1"), oldReviewer, "")
+	s.Require().NoError(err)
+	s.NotEqual(oldReviewer, newReviewer)
+	s.NotEqual("author", newReviewer)
+
+	reviewers := s.getPR("pr-1").AssignedReviewers
+	s.Contains(reviewers, newReviewer)
+	s.NotContains(reviewers, oldReviewer)
+
+	_, err = s.svc.ReassignReviewer(s.ctx, prFilter("pr-1"), oldReviewer, "")
+	s.assertDomainError(err, domain.ErrorTypeNotAssigned)
+
+	s.createTeam("frontend", "author-2", "v1", "v2")
+	s.createPR("pr-2", "author-2")
+
+	_, err = s.svc.ReassignReviewer(s.ctx, prFilter("pr-2"), "v1", "")
+	s.assertDomainError(err, domain.ErrorTypeNoCandidate)
+}
+
+func (s *ServiceTestSuite) TestReassignOnMergedPRFails() {
+	s.createTeam("backend", "author", "u1", "u2", "u3")
+	pr := s.createPR("pr-1", "author")
+	s.Require().NoError(s.svc.MergePR(s.ctx, prFilter("pr-1")))
+
+	_, err := s.svc.ReassignReviewer(s.ctx, prFilter("pr-1"), pr.AssignedReviewers[0], "")
+	s.assertDomainError(err, domain.ErrorTypePRMerged)
+}
+
+func (s *ServiceTestSuite) TestMergeRequiresApprovals() {
+	_, err := s.svc.CreateTeam(s.ctx, domain.Team{
+		TeamName:          "backend",
+		RequiredApprovals: 1,
+		Members: []domain.User{
+			{UserID: "author", Username: "author", IsActive: true},
+			{UserID: "u1", Username: "u1", IsActive: true},
+			{UserID: "u2", Username: "u2", IsActive: true},
+		},
+	})
+	s.Require().NoError(err)
+	pr := s.createPR("pr-1", "author")
+
+	err = s.svc.MergePR(s.ctx, prFilter("pr-1"))
+	s.assertDomainError(err, domain.ErrorTypeNotEnoughApprovals)
+
+	_, err = s.svc.SubmitReview(s.ctx, domain.Review{
+		PullRequestID: "pr-1",
+		ReviewerID:    pr.AssignedReviewers[0],
+		State:         domain.ReviewStateApproved,
+	})
+	s.Require().NoError(err)
+
+	ctx := domain.WithCaller(s.ctx, domain.Caller{Name: "ci", Role: domain.RoleUser, UserID: "author"})
+	s.Require().NoError(s.svc.MergePR(ctx, prFilter("pr-1")))
+	s.Require().NoError(s.svc.MergePR(s.ctx, prFilter("pr-1")))
+
+	merged := s.getPR("pr-1")
+	s.Equal(domain.PRStatusMerged, merged.Status)
+	s.NotNil(merged.MergedAt)
+	s.Equal("author", merged.MergedBy)
+}
+
+func (s *ServiceTestSuite) TestApprovalsDoNotSurviveReopen() {
+	_, err := s.svc.CreateTeam(s.ctx, domain.Team{
+		TeamName:          "backend",
+		RequiredReviewers: 1,
+		RequiredApprovals: 1,
+		Members: []domain.User{
+			{UserID: "author", Username: "author", IsActive: true},
+			{UserID: "u1", Username: "u1", IsActive: true},
+		},
+	})
+	s.Require().NoError(err)
+	s.createPR("pr-1", "author")
+
+	pr, err := s.svc.SubmitReview(s.ctx, domain.Review{
+		PullRequestID: "pr-1",
+		ReviewerID:    "u1",
+		State:         domain.ReviewStateApproved,
+	})
+	s.Require().NoError(err)
+	s.Equal(domain.ReviewStateApproved, pr.Reviews[0].State)
+
+	_, err = s.svc.ClosePR(s.ctx, prFilter("pr-1"))
+	s.Require().NoError(err)
+	pr, err = s.svc.ReopenPR(s.ctx, prFilter("pr-1"))
+	s.Require().NoError(err)
+	s.Equal([]string{"u1"}, pr.AssignedReviewers)
+	s.Equal(domain.ReviewStatePending, s.getPR("pr-1").Reviews[0].State)
+
+	err = s.svc.MergePR(s.ctx, prFilter("pr-1"))
+	s.assertDomainError(err, domain.ErrorTypeNotEnoughApprovals)
+
+	_, err = s.svc.SubmitReview(s.ctx, domain.Review{
+		PullRequestID: "pr-1",
+		ReviewerID:    "u1",
+		State:         domain.ReviewStateApproved,
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(s.svc.MergePR(s.ctx, prFilter("pr-1")))
+}
+
+func (s *ServiceTestSuite) TestTopUpReviewers() {
+	s.createTeam("backend", "author", "u1")
+	pr := s.createPR("pr-1", "author")
+	s.Equal([]string{"u1"}, pr.AssignedReviewers)
+
+	s.Require().NoError(s.repos.Users.Create(s.ctx, domain.User{
+		UserID: "u2", Username: "u2", TeamName: "backend", IsActive: true,
+	}))
+
+	pr, err := s.svc.TopUpReviewers(s.ctx, prFilter("pr-1"))
+	s.Require().NoError(err)
+	s.ElementsMatch([]string{"u1", "u2"}, pr.AssignedReviewers)
+}
+
+func (s *ServiceTestSuite) TestFallbackTeamFillsMissingReviewers() {
+	s.createTeam("platform", "p1", "p2")
+	_, err := s.svc.CreateTeam(s.ctx, domain.Team{
+		TeamName:      "backend",
+		FallbackTeams: []string{"platform"},
+		Members: []domain.User{
+			{UserID: "author", Username: "author", IsActive: true},
+			{UserID: "u1", Username: "u1", IsActive: true},
+		},
+	})
+	s.Require().NoError(err)
+
+	pr := s.createPR("pr-1", "author")
+	s.Len(pr.AssignedReviewers, 2)
+	s.Contains(pr.AssignedReviewers, "u1")
+	s.Len(pr.FallbackReviewers, 1)
+	s.Contains([]string{"p1", "p2"}, pr.FallbackReviewers[0])
+
+	_, err = s.svc.AddTeamMembers(s.ctx, domain.TeamFilter{TeamName: ptr("backend")}, []domain.User{
+		{UserID: "u2", Username: "u2", IsActive: true},
+	})
+	s.Require().NoError(err)
+
+	newReviewerID, err := s.svc.ReassignReviewer(s.ctx, prFilter("pr-1"), pr.FallbackReviewers[0], "")
+	s.Require().NoError(err)
+	s.Equal("u2", newReviewerID)
+	s.Empty(s.getPR("pr-1").FallbackReviewers)
+}
+
+func (s *ServiceTestSuite) TestDeactivationReassignsOpenReviews() {
+	s.createTeam("backend", "author", "u1", "u2", "u3")
+	pr := s.createPR("pr-1", "author")
+
+	leaving := pr.AssignedReviewers[0]
+	report, err := s.svc.SetUserActive(s.ctx, domain.UserFilter{UserID: &leaving}, false)
+	s.Require().NoError(err)
+	s.Equal([]string{leaving}, report.DeactivatedUsers)
+	s.Require().Len(report.Reassigned, 1)
+	s.Empty(report.NoCandidate)
+
+	reviewers := s.getPR("pr-1").AssignedReviewers
+	s.Len(reviewers, 2)
+	s.NotContains(reviewers, leaving)
+	s.Contains(reviewers, report.Reassigned[0].NewReviewerID)
+}
+
+func (s *ServiceTestSuite) TestDeactivationPrefersReviewersTeam() {
+	s.createTeam("platform", "p1", "p2")
+	_, err := s.svc.CreateTeam(s.ctx, domain.Team{
+		TeamName:      "backend",
+		FallbackTeams: []string{"platform"},
+		Members: []domain.User{
+			{UserID: "author", Username: "author", IsActive: true},
+			{UserID: "u1", Username: "u1", IsActive: true},
+		},
+	})
+	s.Require().NoError(err)
+	pr := s.createPR("pr-1", "author")
+	s.Require().Len(pr.FallbackReviewers, 1)
+
+	_, err = s.svc.AddTeamMembers(s.ctx, domain.TeamFilter{TeamName: ptr("backend")}, []domain.User{
+		{UserID: "u2", Username: "u2", IsActive: true},
+	})
+	s.Require().NoError(err)
+
+	leaving := pr.FallbackReviewers[0]
+	report, err := s.svc.SetUserActive(s.ctx, domain.UserFilter{UserID: &leaving}, false)
+	s.Require().NoError(err)
+	s.Require().Len(report.Reassigned, 1)
+
+	replacement := report.Reassigned[0].NewReviewerID
+	s.Contains([]string{"p1", "p2"}, replacement)
+	s.NotEqual(leaving, replacement)
+	s.Equal([]string{replacement}, s.getPR("pr-1").FallbackReviewers)
+}
+
+func (s *ServiceTestSuite) TestBulkDeactivationWithoutCandidate() {
+	s.createTeam("backend", "author", "u1", "u2")
+	s.createPR("pr-1", "author")
+
+	report, err := s.svc.DeactivateTeamUsers(s.ctx, domain.TeamFilter{TeamName: ptr("backend")}, []string{"u1", "u2"})
+	s.Require().NoError(err)
+	s.ElementsMatch([]string{"u1", "u2"}, report.DeactivatedUsers)
+	s.Len(report.NoCandidate, 2)
+	s.Empty(s.getPR("pr-1").AssignedReviewers)
+
+	_, err = s.svc.DeactivateTeamUsers(s.ctx, domain.TeamFilter{TeamName: ptr("backend")}, []string{"unknown"})
+	s.assertDomainError(err, domain.ErrorTypeNotFound)
+}
+
+func (s *ServiceTestSuite) TestListPRsPaginates() {
+	s.createTeam("backend", "author", "u1", "u2")
+	for i := 0; i < 5; i++ {
+		s.createPR(fmt.Sprintf("pr-%d", i), "author")
+	}
+
+	seen := make(map[string]bool)
+	filter := domain.PRFilter{AuthorID: ptr("author"), Limit: 2}
+	for pages := 0; ; pages++ {
+		s.Require().Less(pages, 5)
+
+		prs, next, err := s.svc.ListPRs(s.ctx, filter)
+		s.Require().NoError(err)
+		for _, pr := range prs {
+			s.False(seen[pr.PullRequestID], pr.PullRequestID)
+			seen[pr.PullRequestID] = true
+		}
+		if next == nil {
+			break
+		}
+		filter.After = next
+	}
+	s.Len(seen, 5)
+
+	_, _, err := s.svc.ListPRs(s.ctx, domain.PRFilter{Limit: domain.MaxPRPageSize + 1})
+	s.assertDomainError(err, domain.ErrorTypeValidation)
+}
+
+func (s *ServiceTestSuite) TestStats() {
+	s.createTeam("backend", "author", "u1", "u2")
+	s.createPR("pr-1", "author")
+	s.createPR("pr-2", "author")
+	s.Require().NoError(s.svc.MergePR(s.ctx, prFilter("pr-2")))
+
+	stats, err := s.svc.GetStats(s.ctx, domain.StatsFilter{TeamName: ptr("backend"), Limit: 1})
+	s.Require().NoError(err)
+	s.Require().Len(stats.PullRequests, 1)
+	s.Equal("pr-2", stats.PullRequests[0].PullRequestID)
+
+	_, err = s.svc.GetStats(s.ctx, domain.StatsFilter{Limit: domain.MaxStatsPRLimit + 1})
+	s.assertDomainError(err, domain.ErrorTypeValidation)
+
+	stats, err = s.svc.GetStats(s.ctx, domain.StatsFilter{TeamName: ptr("backend")})
+	s.Require().NoError(err)
+
+	s.Require().Len(stats.Teams, 1)
+	s.Equal(1, stats.Teams[0].OpenPRs)
+	s.Len(stats.PullRequests, 2)
+	s.NotNil(stats.AverageTimeToMergeSeconds)
+	for _, user := range stats.Users {
+		if user.UserID == "author" {
+			continue
+		}
+		s.Equal(1, user.CurrentAssignments, user.UserID)
+		s.Equal(2, user.TotalAssignments, user.UserID)
+	}
+
+	_, err = s.svc.GetStats(s.ctx, domain.StatsFilter{TeamName: ptr("unknown")})
+	s.assertDomainError(err, domain.ErrorTypeNotFound)
+}
+
+func (s *ServiceTestSuite) TestAddTeamMembers() {
+	s.createTeam("backend", "u1")
+	s.createTeam("frontend", "f1")
+
+	team, err := s.svc.AddTeamMembers(s.ctx, domain.TeamFilter{TeamName: ptr("backend")}, []domain.User{
+		{UserID: "u2", Username: "u2", IsActive: true},
+	})
+	s.Require().NoError(err)
+	s.Len(team.Members, 2)
+
+	_, err = s.svc.AddTeamMembers(s.ctx, domain.TeamFilter{TeamName: ptr("backend")}, []domain.User{
+		{UserID: "u3", Username: "u3", IsActive: true},
+		{UserID: "f1", Username: "f1", IsActive: true},
+	})
+	s.assertDomainError(err, domain.ErrorTypeUserInOtherTeam)
+
+	_, err = s.svc.GetUserByID(s.ctx, "u3")
+	s.Error(err)
+}
+
+func (s *ServiceTestSuite) TestRemoveTeamMemberReassignsReviews() {
+	s.createTeam("backend", "author", "u1", "u2", "u3")
+	pr := s.createPR("pr-1", "author")
+	leaving := pr.AssignedReviewers[0]
+
+	_, err := s.svc.RemoveTeamMember(s.ctx, domain.TeamFilter{TeamName: ptr("backend")}, "author")
+	s.assertDomainError(err, domain.ErrorTypeValidation)
+
+	report, err := s.svc.RemoveTeamMember(s.ctx, domain.TeamFilter{TeamName: ptr("backend")}, leaving)
+	s.Require().NoError(err)
+	s.Require().Len(report.Reassigned, 1)
+	s.NotContains(s.getPR("pr-1").AssignedReviewers, leaving)
+
+	team, err := s.svc.GetTeam(s.ctx, domain.TeamFilter{TeamName: ptr("backend")})
+	s.Require().NoError(err)
+	s.Len(team.Members, 3)
+
+	s.createTeam("frontend")
+	team, err = s.svc.AddTeamMembers(s.ctx, domain.TeamFilter{TeamName: ptr("frontend")}, []domain.User{
+		{UserID: leaving, Username: leaving, IsActive: true},
+	})
+	s.Require().NoError(err)
+	s.Len(team.Members, 1)
+}
+
+func (s *ServiceTestSuite) TestMoveUserToTeam() {
+	s.createTeam("backend", "author", "u1", "u2", "u3")
+	s.createTeam("frontend", "f1")
+	pr := s.createPR("pr-1", "author")
+	moving := pr.AssignedReviewers[0]
+
+	user, report, err := s.svc.MoveUserToTeam(s.ctx, domain.UserFilter{UserID: &moving}, "frontend", "renamed")
+	s.Require().NoError(err)
+	s.Equal("frontend", user.TeamName)
+	s.Equal("renamed", user.Username)
+	s.True(user.IsActive)
+	s.Require().Len(report.Reassigned, 1)
+	s.NotContains(s.getPR("pr-1").AssignedReviewers, moving)
+
+	history, err := s.svc.GetAssignmentHistory(s.ctx, prFilter("pr-1"))
+	s.Require().NoError(err)
+	s.Equal(domain.AssignmentOperationMembership, history[len(history)-1].Operation)
+
+	_, _, err = s.svc.MoveUserToTeam(s.ctx, domain.UserFilter{UserID: &moving}, "missing", "")
+	s.assertDomainError(err, domain.ErrorTypeNotFound)
+}
+
+func (s *ServiceTestSuite) TestMoveUserToTeamKeepsOtherTeamsReviews() {
+	s.createTeam("platform", "p1")
+	_, err := s.svc.CreateTeam(s.ctx, domain.Team{
+		TeamName:      "backend",
+		FallbackTeams: []string{"platform"},
+		Members: []domain.User{
+			{UserID: "author", Username: "author", IsActive: true},
+			{UserID: "u1", Username: "u1", IsActive: true},
+		},
+	})
+	s.Require().NoError(err)
+	s.createTeam("frontend", "f1", "f2")
+	s.createPR("pr-1", "author")
+	s.Require().Contains(s.getPR("pr-1").AssignedReviewers, "p1")
+	s.createPR("pr-2", "p1")
+
+	user, report, err := s.svc.MoveUserToTeam(s.ctx, domain.UserFilter{UserID: ptr("p1")}, "frontend", "")
+	s.Require().NoError(err)
+	s.Equal("frontend", user.TeamName)
+	s.Empty(report.Reassigned)
+	s.Empty(report.NoCandidate)
+	s.Contains(s.getPR("pr-1").AssignedReviewers, "p1")
+
+	pr, err := s.svc.GetPR(s.ctx, prFilter("pr-2"))
+	s.Require().NoError(err)
+	s.Equal("p1", pr.AuthorID)
+	s.Equal(domain.PRStatusOpen, pr.Status)
+}
+
+func (s *ServiceTestSuite) TestArchiveTeam() {
+	s.createTeam("backend", "author", "u1", "u2")
+	s.createTeam("platform", "p1")
+	_, err := s.svc.SetFallbackTeams(s.ctx, domain.TeamFilter{TeamName: ptr("backend")}, []string{"platform"})
+	s.Require().NoError(err)
+	s.createPR("pr-1", "author")
+	s.createPR("pr-2", "author")
+
+	report, err := s.svc.DeleteTeam(s.ctx, domain.TeamFilter{TeamName: ptr("backend")}, domain.TeamDeleteOptions{})
+	s.Require().NoError(err)
+	s.True(report.Archived)
+	s.Empty(report.ClosedPullRequests)
+	s.ElementsMatch([]string{"author", "u1", "u2"}, report.DeactivatedUsers)
+	s.Equal([]string{"p1"}, s.getPR("pr-1").AssignedReviewers)
+
+	_, err = s.svc.GetTeam(s.ctx, domain.TeamFilter{TeamName: ptr("backend")})
+	s.assertDomainError(err, domain.ErrorTypeNotFound)
+
+	team, err := s.svc.GetTeam(s.ctx, domain.TeamFilter{TeamName: ptr("backend"), IncludeArchived: true})
+	s.Require().NoError(err)
+	s.NotNil(team.ArchivedAt)
+	for _, member := range team.Members {
+		s.False(member.IsActive)
+	}
+
+	stats, err := s.svc.GetStats(s.ctx, domain.StatsFilter{})
+	s.Require().NoError(err)
+	s.Require().Len(stats.Teams, 1)
+	s.Equal("platform", stats.Teams[0].TeamName)
+
+	_, err = s.svc.CreateTeam(s.ctx, domain.Team{TeamName: "backend"})
+	s.assertDomainError(err, domain.ErrorTypeTeamExists)
+}
+
+func (s *ServiceTestSuite) TestArchiveTeamClosesOpenPRs() {
+	s.createTeam("backend", "author", "u1", "u2")
+	s.createPR("pr-1", "author")
+
+	report, err := s.svc.DeleteTeam(s.ctx, domain.TeamFilter{TeamName: ptr("backend")},
+		domain.TeamDeleteOptions{CloseOpenPRs: true})
+	s.Require().NoError(err)
+	s.Equal([]string{"pr-1"}, report.ClosedPullRequests)
+	s.Empty(report.NoCandidate)
+
+	pr := s.getPR("pr-1")
+	s.Equal(domain.PRStatusClosed, pr.Status)
+	s.Empty(pr.AssignedReviewers)
+}
+
+func (s *ServiceTestSuite) TestHardDeleteTeam() {
+	s.createTeam("backend", "author", "u1")
+	s.createPR("pr-1", "author")
+
+	_, err := s.svc.DeleteTeam(s.ctx, domain.TeamFilter{TeamName: ptr("backend")}, domain.TeamDeleteOptions{Hard: true})
+	s.assertDomainError(err, domain.ErrorTypeTeamHasPRs)
+
+	s.createTeam("empty", "e1")
+	report, err := s.svc.DeleteTeam(s.ctx, domain.TeamFilter{TeamName: ptr("empty")}, domain.TeamDeleteOptions{Hard: true})
+	s.Require().NoError(err)
+	s.False(report.Archived)
+
+	_, err = s.svc.GetTeam(s.ctx, domain.TeamFilter{TeamName: ptr("empty"), IncludeArchived: true})
+	s.assertDomainError(err, domain.ErrorTypeNotFound)
+	_, err = s.svc.GetUserByID(s.ctx, "e1")
+	s.Error(err)
+}
+
+func (s *ServiceTestSuite) TestAPITokens() {
+	s.createTeam("backend", "u1")
+
+	_, _, err := s.svc.CreateToken(s.ctx, domain.APIToken{Name: "bot", Role: domain.RoleUser})
+	s.assertDomainError(err, domain.ErrorTypeValidation)
+
+	secret, token, err := s.svc.CreateToken(s.ctx, domain.APIToken{Name: "u1-cli", Role: domain.RoleUser, UserID: "u1"})
+	s.Require().NoError(err)
+	s.NotContains(token.TokenHash, secret)
+
+	caller, err := s.svc.Authenticate(s.ctx, secret)
+	s.Require().NoError(err)
+	s.Equal(domain.Caller{TokenID: token.ID, Name: "u1-cli", Role: domain.RoleUser, UserID: "u1"}, *caller)
+
+	s.Require().NoError(s.svc.RevokeToken(s.ctx, token.ID))
+	_, err = s.svc.Authenticate(s.ctx, secret)
+	s.assertDomainError(err, domain.ErrorTypeUnauthorized)
+	s.assertDomainError(s.svc.RevokeToken(s.ctx, token.ID), domain.ErrorTypeNotFound)
+
+	bootstrap := domain.APIToken{Name: "bootstrap", Role: domain.RoleAdmin}
+	s.Require().NoError(s.svc.EnsureToken(s.ctx, bootstrap, "bootstrap-secret-value"))
+	s.Require().NoError(s.svc.EnsureToken(s.ctx, bootstrap, "bootstrap-secret-value"))
+	tokens, err := s.svc.ListTokens(s.ctx)
+	s.Require().NoError(err)
+	s.Len(tokens, 2)
+
+	caller, err = s.svc.Authenticate(s.ctx, "bootstrap-secret-value")
+	s.Require().NoError(err)
+	s.Equal("token:bootstrap", caller.Identity())
+
+	_, err = s.svc.Authenticate(s.ctx, "unknown-secret-value")
+	s.assertDomainError(err, domain.ErrorTypeUnauthorized)
+}
+
+type failingTokens struct {
+	domain.TokenRepository
+}
+
+func (failingTokens) FindByHash(context.Context, string) (*domain.APIToken, error) {
+	return nil, errDatabaseDown
+}
+
+var errDatabaseDown = errors.New("connection refused")
+
+func (s *ServiceTestSuite) TestAuthenticateReportsStorageFailures() {
+	repos := s.repos
+	repos.Tokens = failingTokens{repos.Tokens}
+	svc := service.NewService(repos, nil, service.NewLeastLoadedSelector(), service.NewTeamPolicy(), nil)
+
+	_, err := svc.Authenticate(s.ctx, "any-secret-value")
+	s.ErrorIs(err, errDatabaseDown)
+	s.ErrorIs(svc.EnsureToken(s.ctx, domain.APIToken{Name: "bootstrap", Role: domain.RoleAdmin}, "any-secret-value"),
+		errDatabaseDown)
+}
+
+func (s *ServiceTestSuite) TestPRPolicy() {
+	s.createTeam("backend", "author", "u1", "u2", "lead", "outsider")
+	_, err := s.svc.SetUserRole(s.ctx, domain.UserFilter{UserID: ptr("lead")}, domain.UserRoleLead)
+	s.Require().NoError(err)
+	_, err = s.svc.SetUserRole(s.ctx, domain.UserFilter{UserID: ptr("u1")}, "OWNER")
+	s.assertDomainError(err, domain.ErrorTypeValidation)
+
+	pr := s.createPR("pr-1", "author")
+	as := func(userID string) context.Context {
+		return domain.WithCaller(s.ctx, domain.Caller{Name: userID, Role: domain.RoleUser, UserID: userID})
+	}
+
+	var outsider string
+	for _, userID := range []string{"u1", "u2", "lead", "outsider"} {
+		if userID != "lead" && !contains(pr.AssignedReviewers, userID) {
+			outsider = userID
+		}
+	}
+	s.Require().NotEmpty(outsider)
+
+	_, err = s.svc.ReassignReviewer(as(outsider), prFilter("pr-1"), pr.AssignedReviewers[0], "")
+	s.assertDomainError(err, domain.ErrorTypeForbidden)
+	s.assertDomainError(s.svc.MergePR(as(outsider), prFilter("pr-1")), domain.ErrorTypeForbidden)
+	_, err = s.svc.ClosePR(as(outsider), prFilter("pr-1"))
+	s.assertDomainError(err, domain.ErrorTypeForbidden)
+	_, err = s.svc.TopUpReviewers(as(outsider), prFilter("pr-1"))
+	s.assertDomainError(err, domain.ErrorTypeForbidden)
+
+	_, err = s.svc.ClosePR(as("author"), prFilter("pr-1"))
+	s.Require().NoError(err)
+	_, err = s.svc.ReopenPR(as(outsider), prFilter("pr-1"))
+	s.assertDomainError(err, domain.ErrorTypeForbidden)
+	pr, err = s.svc.ReopenPR(as("lead"), prFilter("pr-1"))
+	s.Require().NoError(err)
+
+	_, err = s.svc.ReassignReviewer(as(pr.AssignedReviewers[0]), prFilter("pr-1"), pr.AssignedReviewers[0], "")
+	s.Require().NoError(err)
+	s.Require().NoError(s.svc.MergePR(as("lead"), prFilter("pr-1")))
+	s.Equal("lead", s.getPR("pr-1").MergedBy)
+}
+
+func (s *ServiceTestSuite) TestUserTokensActOnlyAsTheirUser() {
+	s.createTeam("backend", "author", "u1", "u2")
+	pr := s.createPR("pr-1", "author")
+	as := func(userID string) context.Context {
+		return domain.WithCaller(s.ctx, domain.Caller{Name: userID, Role: domain.RoleUser, UserID: userID})
+	}
+	approve := func(ctx context.Context, reviewerID string) error {
+		_, err := s.svc.SubmitReview(ctx, domain.Review{
+			PullRequestID: "pr-1",
+			ReviewerID:    reviewerID,
+			State:         domain.ReviewStateApproved,
+		})
+		return err
+	}
+
+	s.assertDomainError(approve(as("author"), pr.AssignedReviewers[0]), domain.ErrorTypeForbidden)
+	s.assertDomainError(approve(as(pr.AssignedReviewers[1]), pr.AssignedReviewers[0]), domain.ErrorTypeForbidden)
+	s.Require().NoError(approve(as(pr.AssignedReviewers[0]), pr.AssignedReviewers[0]))
+	admin := domain.WithCaller(s.ctx, domain.Caller{Name: "ops", Role: domain.RoleAdmin})
+	s.Require().NoError(approve(admin, pr.AssignedReviewers[1]))
+
+	_, err := s.svc.CreatePR(as("u1"), domain.PullRequest{PullRequestID: "pr-2", PullRequestName: "pr-2", AuthorID: "author"})
+	s.assertDomainError(err, domain.ErrorTypeForbidden)
+	_, err = s.svc.CreatePR(as("author"), domain.PullRequest{PullRequestID: "pr-2", PullRequestName: "pr-2", AuthorID: "author"})
+	s.Require().NoError(err)
+}
+
+type recordedEvents struct {
+	created, opened, understaffed, merged, reassigned, noCandidate int
+}
+
+func (e *recordedEvents) PRCreated(assignedReviewers, requiredReviewers int) {
+	e.created++
+	if assignedReviewers < requiredReviewers {
+		e.understaffed++
+	}
+}
+
+func (e *recordedEvents) PROpened(assignedReviewers, requiredReviewers int) {
+	e.opened++
+	if assignedReviewers < requiredReviewers {
+		e.understaffed++
+	}
+}
+
+func (e *recordedEvents) PRMerged()                     { e.merged++ }
+func (e *recordedEvents) ReviewersReassigned(count int) { e.reassigned += count }
+func (e *recordedEvents) NoCandidate()                  { e.noCandidate++ }
+
+func (s *ServiceTestSuite) TestEventsAreRecordedAfterCommit() {
+	events := &recordedEvents{}
+	store := memory.NewStore()
+	s.repos = memory.NewRepositories(store)
+	s.svc = service.NewService(
+		s.repos, memory.NewTxManager(store), service.NewLeastLoadedSelector(), service.NewTeamPolicy(), events,
+	)
+
+	s.createTeam("backend", "author", "u1")
+	s.createPR("pr-1", "author")
+	_, err := s.svc.CreatePR(s.ctx, domain.PullRequest{PullRequestID: "pr-1", PullRequestName: "pr-1", AuthorID: "author"})
+	s.assertDomainError(err, domain.ErrorTypePRExists)
+
+	_, err = s.svc.ReassignReviewer(s.ctx, prFilter("pr-1"), "u1", "")
+	s.assertDomainError(err, domain.ErrorTypeNoCandidate)
+
+	_, err = s.svc.AddTeamMembers(s.ctx, domain.TeamFilter{TeamName: ptr("backend")},
+		[]domain.User{{UserID: "u2", Username: "u2", IsActive: true}})
+	s.Require().NoError(err)
+	_, err = s.svc.ReassignReviewer(s.ctx, prFilter("pr-1"), "u1", "")
+	s.Require().NoError(err)
+	_, err = s.svc.SetUserActive(s.ctx, domain.UserFilter{UserID: ptr("u2")}, false)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.svc.MergePR(s.ctx, prFilter("pr-1")))
+	s.Require().NoError(s.svc.MergePR(s.ctx, prFilter("pr-1")))
+
+	_, err = s.svc.CreatePR(s.ctx, domain.PullRequest{PullRequestID: "pr-2", AuthorID: "author", Status: domain.PRStatusDraft})
+	s.Require().NoError(err)
+	pr, err := s.svc.MarkPRReady(s.ctx, prFilter("pr-2"))
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"u1"}, pr.AssignedReviewers)
+	report, err := s.svc.SetUserActive(s.ctx, domain.UserFilter{UserID: ptr("u1")}, false)
+	s.Require().NoError(err)
+	s.Require().Len(report.NoCandidate, 1)
+
+	s.Equal(recordedEvents{created: 2, opened: 1, understaffed: 2, merged: 1, reassigned: 2, noCandidate: 2}, *events)
+}
+
+func contains(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
+
+func ptr(value string) *string {
+	return &value
+}
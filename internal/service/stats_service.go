@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+func (s *Service) GetStats(ctx context.Context, filter domain.StatsFilter) (*domain.Stats, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, domain.NewValidationError("from must be before to")
+	}
+	if filter.Limit < 0 || filter.Limit > domain.MaxStatsPRLimit {
+		return nil, domain.NewValidationError("limit must be between 1 and 1000")
+	}
+	if filter.Limit == 0 {
+		filter.Limit = domain.DefaultStatsPRLimit
+	}
+	if filter.TeamName != nil {
+		exists, err := s.repos.Teams.Exists(ctx, domain.TeamFilter{TeamName: filter.TeamName})
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, domain.NewNotFoundError("team")
+		}
+	}
+
+	users, err := s.repos.Stats.UserAssignments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := s.repos.Stats.OpenPRsByTeam(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	prs, err := s.repos.Stats.ReviewersPerPR(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	averageTimeToMerge, err := s.repos.Stats.AverageTimeToMerge(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Stats{
+		Users:                     users,
+		Teams:                     teams,
+		PullRequests:              prs,
+		AverageTimeToMergeSeconds: averageTimeToMerge,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+
+	"github.com/nikitaenmi/AvitoTest/internal/domain"
+)
+
+// DeleteTeam archives a team by default: its members are deactivated, their
+// open reviews are handed to other reviewers, and the team is hidden from
+// lookups. Open pull requests authored by the team are closed when requested
+// and keep their (reassigned) reviewers otherwise. A hard delete removes the
+// team and its members and is only allowed when no pull request references them.
+func (s *Service) DeleteTeam(
+	ctx context.Context, filter domain.TeamFilter, opts domain.TeamDeleteOptions,
+) (*domain.TeamDeletionReport, error) {
+	if filter.TeamName == nil || *filter.TeamName == "" {
+		return nil, domain.NewValidationError("team name cannot be empty")
+	}
+	filter.IncludeArchived = opts.Hard
+
+	var report *domain.TeamDeletionReport
+	err := s.withinTxRetry(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		report = &domain.TeamDeletionReport{
+			TeamName:           *filter.TeamName,
+			ClosedPullRequests: []string{},
+			DeactivationReport: *newDeactivationReport(),
+		}
+
+		team, err := repos.Teams.FindOne(ctx, filter)
+		if err != nil {
+			return domain.NewNotFoundError("team")
+		}
+
+		if !opts.Hard {
+			return s.archiveTeam(ctx, repos, team, opts.CloseOpenPRs, report)
+		}
+
+		if err := repos.Teams.LockMembers(ctx, team.TeamName); err != nil {
+			return err
+		}
+		hasPRs, err := repos.Teams.HasPullRequests(ctx, team.TeamName)
+		if err != nil {
+			return err
+		}
+		if hasPRs {
+			return domain.NewTeamHasPRsError()
+		}
+		return repos.Teams.Delete(ctx, team.TeamName)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordReleased(&report.DeactivationReport)
+	return report, nil
+}
+
+func (s *Service) archiveTeam(
+	ctx context.Context, repos domain.Repositories, team *domain.Team, closeOpenPRs bool,
+	report *domain.TeamDeletionReport,
+) error {
+	report.Archived = true
+
+	if closeOpenPRs {
+		for _, status := range []string{domain.PRStatusOpen, domain.PRStatusDraft} {
+			prs, err := repos.PRs.FindAll(ctx, domain.PRFilter{TeamName: &team.TeamName, Status: &status})
+			if err != nil {
+				return err
+			}
+			for i := range prs {
+				if err := s.closePR(ctx, repos, &prs[i], "team archived"); err != nil {
+					return err
+				}
+				report.ClosedPullRequests = append(report.ClosedPullRequests, prs[i].PullRequestID)
+			}
+		}
+	}
+
+	userIDs := make([]string, len(team.Members))
+	for i, member := range team.Members {
+		userIDs[i] = member.UserID
+	}
+	if err := repos.Users.SetActive(ctx, userIDs, false); err != nil {
+		return err
+	}
+	if err := s.releaseReviewers(ctx, repos, team.Members, releaseTeamArchived, &report.DeactivationReport); err != nil {
+		return err
+	}
+
+	return repos.Teams.Archive(ctx, team.TeamName)
+}
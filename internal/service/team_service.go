@@ -2,40 +2,126 @@ package service
 
 import (
 	"context"
+	"errors"
 
 	"github.com/nikitaenmi/AvitoTest/internal/domain"
 )
 
 func (s *Service) CreateTeam(ctx context.Context, team domain.Team) (*domain.Team, error) {
-	exists, err := s.teamRepo.Exists(ctx, domain.TeamFilter{TeamName: &team.TeamName})
-	if err != nil {
-		return nil, err
+	if team.RequiredReviewers < 0 {
+		return nil, domain.NewValidationError("required reviewers cannot be negative")
+	}
+	if team.RequiredReviewers == 0 {
+		team.RequiredReviewers = domain.DefaultRequiredReviewers
 	}
-	if exists {
-		return nil, domain.NewTeamExistsError()
+	if team.RequiredApprovals < 0 {
+		return nil, domain.NewValidationError("required approvals cannot be negative")
+	}
+	if team.RequiredApprovals > team.RequiredReviewers {
+		return nil, domain.NewValidationError("required approvals cannot exceed required reviewers")
 	}
 
-	if err := s.teamRepo.Create(ctx, team); err != nil {
+	if err := validateMembers(team.Members); err != nil {
 		return nil, err
 	}
 
-	for i := range team.Members {
-		team.Members[i].TeamName = team.TeamName
-		if err := s.userRepo.Create(ctx, team.Members[i]); err != nil {
-			return nil, err
+	var created *domain.Team
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		exists, err := repos.Teams.Exists(ctx, domain.TeamFilter{TeamName: &team.TeamName})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return domain.NewTeamExistsError()
+		}
+
+		if err := s.validateFallbackTeams(ctx, repos, team.TeamName, team.FallbackTeams); err != nil {
+			return err
+		}
+
+		if err := repos.Teams.Create(ctx, team); err != nil {
+			if errors.Is(err, domain.ErrDuplicateKey) {
+				return domain.NewTeamExistsError()
+			}
+			return err
+		}
+
+		if err := s.addMembers(ctx, repos, team.TeamName, team.Members); err != nil {
+			return err
 		}
+
+		if err := repos.Teams.SetFallbackTeams(ctx, team.TeamName, team.FallbackTeams); err != nil {
+			return err
+		}
+
+		created, err = repos.Teams.FindOne(ctx, domain.TeamFilter{TeamName: &team.TeamName})
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return s.teamRepo.FindOne(ctx, domain.TeamFilter{TeamName: &team.TeamName})
+	return created, nil
 }
 
 func (s *Service) GetTeam(ctx context.Context, filter domain.TeamFilter) (*domain.Team, error) {
 	if filter.TeamName == nil || *filter.TeamName == "" {
 		return nil, domain.NewValidationError("team name cannot be empty")
 	}
-	team, err := s.teamRepo.FindOne(ctx, filter)
+	team, err := s.repos.Teams.FindOne(ctx, filter)
 	if err != nil {
 		return nil, domain.NewNotFoundError("team")
 	}
 	return team, nil
 }
+
+func (s *Service) SetFallbackTeams(ctx context.Context, filter domain.TeamFilter, fallbackTeams []string) (*domain.Team, error) {
+	if filter.TeamName == nil || *filter.TeamName == "" {
+		return nil, domain.NewValidationError("team name cannot be empty")
+	}
+
+	exists, err := s.repos.Teams.Exists(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, domain.NewNotFoundError("team")
+	}
+
+	if err := s.validateFallbackTeams(ctx, s.repos, *filter.TeamName, fallbackTeams); err != nil {
+		return nil, err
+	}
+
+	if err := s.repos.Teams.SetFallbackTeams(ctx, *filter.TeamName, fallbackTeams); err != nil {
+		return nil, err
+	}
+
+	return s.repos.Teams.FindOne(ctx, filter)
+}
+
+func (s *Service) validateFallbackTeams(
+	ctx context.Context, repos domain.Repositories, teamName string, fallbackTeams []string,
+) error {
+	seen := make(map[string]bool, len(fallbackTeams))
+	for _, fallbackTeam := range fallbackTeams {
+		if fallbackTeam == "" {
+			return domain.NewValidationError("fallback team name cannot be empty")
+		}
+		if fallbackTeam == teamName {
+			return domain.NewValidationError("team cannot be its own fallback")
+		}
+		if seen[fallbackTeam] {
+			return domain.NewValidationError("fallback team " + fallbackTeam + " is listed twice")
+		}
+		seen[fallbackTeam] = true
+
+		exists, err := repos.Teams.Exists(ctx, domain.TeamFilter{TeamName: &fallbackTeam})
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return domain.NewNotFoundError("fallback team " + fallbackTeam)
+		}
+	}
+	return nil
+}
@@ -6,18 +6,59 @@ import (
 	"github.com/nikitaenmi/AvitoTest/internal/domain"
 )
 
-func (s *Service) SetUserActive(ctx context.Context, filter domain.UserFilter, isActive bool) error {
+func (s *Service) SetUserActive(
+	ctx context.Context, filter domain.UserFilter, isActive bool,
+) (*domain.DeactivationReport, error) {
 	if filter.UserID == nil || *filter.UserID == "" {
-		return domain.NewValidationError("user ID cannot be empty")
+		return nil, domain.NewValidationError("user ID cannot be empty")
 	}
 
-	user, err := s.userRepo.FindOne(ctx, filter)
+	var report *domain.DeactivationReport
+	err := s.withinTxRetry(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		report = newDeactivationReport()
+
+		user, err := repos.Users.FindOne(ctx, filter)
+		if err != nil {
+			return domain.NewNotFoundError("user")
+		}
+
+		wasActive := user.IsActive
+		user.IsActive = isActive
+		if err := repos.Users.Update(ctx, user); err != nil {
+			return err
+		}
+
+		if isActive || !wasActive {
+			return nil
+		}
+		return s.releaseReviewers(ctx, repos, []domain.User{*user}, releaseDeactivated, report)
+	})
 	if err != nil {
-		return domain.NewNotFoundError("user")
+		return nil, err
 	}
 
-	user.IsActive = isActive
-	return s.userRepo.Update(ctx, user)
+	s.recordReleased(report)
+	return report, nil
+}
+
+func (s *Service) SetUserRole(ctx context.Context, filter domain.UserFilter, role string) (*domain.User, error) {
+	if filter.UserID == nil || *filter.UserID == "" {
+		return nil, domain.NewValidationError("user ID cannot be empty")
+	}
+	if err := validateUserRole(role); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repos.Users.FindOne(ctx, filter)
+	if err != nil {
+		return nil, domain.NewNotFoundError("user")
+	}
+
+	user.Role = role
+	if err := s.repos.Users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 func (s *Service) GetUserReviewPRs(ctx context.Context, filter domain.UserFilter) ([]domain.PullRequest, error) {
@@ -25,16 +66,22 @@ func (s *Service) GetUserReviewPRs(ctx context.Context, filter domain.UserFilter
 		return nil, domain.NewValidationError("user ID cannot be empty")
 	}
 
-	if _, err := s.userRepo.FindOne(ctx, filter); err != nil {
+	if _, err := s.repos.Users.FindOne(ctx, filter); err != nil {
 		return nil, domain.NewNotFoundError("user")
 	}
 
-	return s.prRepo.FindByReviewer(ctx, *filter.UserID)
+	return s.repos.PRs.FindByReviewer(ctx, *filter.UserID)
 }
 
 func (s *Service) GetActiveTeamMembers(ctx context.Context, teamName string, excludeUserIDs ...string) ([]domain.User, error) {
+	return s.activeTeamMembers(ctx, s.repos, teamName, excludeUserIDs...)
+}
+
+func (s *Service) activeTeamMembers(
+	ctx context.Context, repos domain.Repositories, teamName string, excludeUserIDs ...string,
+) ([]domain.User, error) {
 	isActive := true
-	users, err := s.userRepo.FindAll(ctx, domain.UserFilter{
+	users, err := repos.Users.FindAll(ctx, domain.UserFilter{
 		TeamName: &teamName,
 		IsActive: &isActive,
 	})
@@ -63,5 +110,5 @@ func (s *Service) GetUserByID(ctx context.Context, userID string) (*domain.User,
 	if userID == "" {
 		return nil, domain.NewValidationError("user ID cannot be empty")
 	}
-	return s.userRepo.FindOne(ctx, domain.UserFilter{UserID: &userID})
+	return s.repos.Users.FindOne(ctx, domain.UserFilter{UserID: &userID})
 }
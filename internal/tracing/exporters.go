@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// NopExporter drops every span. A tracer built with it is disabled.
+type NopExporter struct{}
+
+func (NopExporter) Export(SpanData)                {}
+func (NopExporter) Shutdown(context.Context) error { return nil }
+
+// StdoutExporter writes every span as one JSON object per line.
+type StdoutExporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{enc: json.NewEncoder(w)}
+}
+
+type jsonSpan struct {
+	TraceID      string         `json:"trace_id"`
+	SpanID       string         `json:"span_id"`
+	ParentSpanID string         `json:"parent_span_id,omitempty"`
+	Name         string         `json:"name"`
+	Kind         string         `json:"kind"`
+	Start        time.Time      `json:"start"`
+	DurationMS   float64        `json:"duration_ms"`
+	Attributes   map[string]any `json:"attributes,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
+
+func (e *StdoutExporter) Export(span SpanData) {
+	out := jsonSpan{
+		TraceID:    span.Context.TraceID.String(),
+		SpanID:     span.Context.SpanID.String(),
+		Name:       span.Name,
+		Kind:       span.Kind.String(),
+		Start:      span.Start,
+		DurationMS: float64(span.End.Sub(span.Start).Microseconds()) / 1000,
+		Error:      span.Error,
+	}
+	if span.ParentSpanID.IsValid() {
+		out.ParentSpanID = span.ParentSpanID.String()
+	}
+	if len(span.Attributes) > 0 {
+		out.Attributes = make(map[string]any, len(span.Attributes))
+		for _, attr := range span.Attributes {
+			out.Attributes[attr.Key] = attr.Value
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.enc.Encode(out); err != nil {
+		slog.Error("failed to export span", slog.Any("error", err))
+	}
+}
+
+func (e *StdoutExporter) Shutdown(context.Context) error {
+	return nil
+}
@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+const TraceparentHeader = "traceparent"
+
+const (
+	traceparentLength = 55
+	flagSampled       = 0x01
+)
+
+// ParseTraceparent reads a W3C Trace Context traceparent header value,
+// "version-traceid-parentid-flags". Unknown future versions are accepted as
+// long as they start with the version 00 fields, as the specification asks.
+func ParseTraceparent(value string) (SpanContext, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) < traceparentLength {
+		return SpanContext{}, false
+	}
+
+	version, ok := decodeHex(value[0:2])
+	if !ok || version[0] == 0xff || value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return SpanContext{}, false
+	}
+	if version[0] == 0 && len(value) != traceparentLength {
+		return SpanContext{}, false
+	}
+	if len(value) > traceparentLength && value[traceparentLength] != '-' {
+		return SpanContext{}, false
+	}
+
+	var sc SpanContext
+	traceID, ok := decodeHex(value[3:35])
+	if !ok {
+		return SpanContext{}, false
+	}
+	copy(sc.TraceID[:], traceID)
+
+	spanID, ok := decodeHex(value[36:52])
+	if !ok {
+		return SpanContext{}, false
+	}
+	copy(sc.SpanID[:], spanID)
+
+	flags, ok := decodeHex(value[53:55])
+	if !ok {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&flagSampled != 0
+
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	return sc, true
+}
+
+// FormatTraceparent renders sc as a version 00 traceparent header value.
+func FormatTraceparent(sc SpanContext) string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// decodeHex accepts lowercase hex only; the specification forbids uppercase.
+func decodeHex(s string) ([]byte, bool) {
+	if strings.ToLower(s) != s {
+		return nil, false
+	}
+	b, err := hex.DecodeString(s)
+	return b, err == nil
+}
@@ -0,0 +1,232 @@
+// Package tracing records spans that follow a request from the HTTP handler
+// through the service into every repository call. Spans travel in the context
+// and are handed to an Exporter when they end.
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+type TraceID [16]byte
+
+func (id TraceID) IsValid() bool  { return id != TraceID{} }
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+type SpanID [8]byte
+
+func (id SpanID) IsValid() bool  { return id != SpanID{} }
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanContext identifies a span across process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindServer
+)
+
+func (k SpanKind) String() string {
+	if k == SpanKindServer {
+		return "server"
+	}
+	return "internal"
+}
+
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+func String(key, value string) Attribute  { return Attribute{Key: key, Value: value} }
+func Int(key string, value int) Attribute { return Attribute{Key: key, Value: value} }
+
+// SpanData is the finished span passed to exporters.
+type SpanData struct {
+	Name         string
+	Kind         SpanKind
+	Context      SpanContext
+	ParentSpanID SpanID
+	Start        time.Time
+	End          time.Time
+	Attributes   []Attribute
+	Error        string
+}
+
+// Span is an operation in progress. A nil Span is valid and records nothing,
+// which is what a disabled tracer hands out.
+type Span struct {
+	tracer *Tracer
+	mu     sync.Mutex
+	data   SpanData
+	ended  bool
+}
+
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.Context
+}
+
+func (s *Span) SetAttributes(attributes ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes = append(s.data.Attributes, attributes...)
+}
+
+// RecordError marks the span as failed. Nil errors are ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Error = err.Error()
+}
+
+// End finishes the span and exports it if it is sampled. Only the first call
+// has an effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if data.Context.Sampled {
+		s.tracer.exporter.Export(data)
+	}
+}
+
+type Exporter interface {
+	Export(span SpanData)
+	Shutdown(ctx context.Context) error
+}
+
+type Tracer struct {
+	exporter Exporter
+}
+
+// NewTracer returns a tracer that hands finished spans to exporter. With a nil
+// exporter or NopExporter the tracer is disabled and starts no spans.
+func NewTracer(exporter Exporter) *Tracer {
+	if _, ok := exporter.(NopExporter); ok || exporter == nil {
+		return &Tracer{}
+	}
+	return &Tracer{exporter: exporter}
+}
+
+func (t *Tracer) Enabled() bool {
+	return t != nil && t.exporter != nil
+}
+
+// Start begins a span that is a child of the span in ctx or, failing that, of
+// the remote parent extracted from an incoming request.
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	if !t.Enabled() {
+		return ctx, nil
+	}
+
+	parent, ok := parentFromContext(ctx)
+	sc := SpanContext{TraceID: parent.TraceID, SpanID: newSpanID(), Sampled: parent.Sampled}
+	if !ok {
+		sc.TraceID = newTraceID()
+		sc.Sampled = true
+	}
+
+	span := &Span{tracer: t, data: SpanData{
+		Name:    name,
+		Kind:    kind,
+		Context: sc,
+		Start:   time.Now(),
+	}}
+	if ok {
+		span.data.ParentSpanID = parent.SpanID
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// StartQuery traces a call to method of component, such as a repository. It
+// makes Tracer an observer for the instrumented repositories.
+func (t *Tracer) StartQuery(ctx context.Context, component, method string) (context.Context, func(error)) {
+	ctx, span := t.Start(ctx, component+"."+method, SpanKindInternal)
+	return ctx, func(err error) {
+		span.RecordError(err)
+		span.End()
+	}
+}
+
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if !t.Enabled() {
+		return nil
+	}
+	return t.exporter.Shutdown(ctx)
+}
+
+type spanKey struct{}
+
+type remoteParentKey struct{}
+
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ContextWithRemoteParent makes sc the parent of the next span started from
+// ctx. It is used for the span context received in a traceparent header.
+func ContextWithRemoteParent(ctx context.Context, sc SpanContext) context.Context {
+	if !sc.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteParentKey{}, sc)
+}
+
+func parentFromContext(ctx context.Context) (SpanContext, bool) {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext(), true
+	}
+	sc, ok := ctx.Value(remoteParentKey{}).(SpanContext)
+	return sc, ok
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(id[8:], rand.Uint64())
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return id
+}
@@ -0,0 +1,179 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nikitaenmi/AvitoTest/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (e *recordingExporter) Export(span tracing.SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error { return nil }
+
+func TestParseTraceparent(t *testing.T) {
+	const valid = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	sc, ok := tracing.ParseTraceparent(valid)
+	require.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.Sampled)
+	assert.Equal(t, valid, tracing.FormatTraceparent(sc))
+
+	sc, ok = tracing.ParseTraceparent("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future")
+	require.True(t, ok)
+	assert.False(t, sc.Sampled)
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+	} {
+		_, ok := tracing.ParseTraceparent(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestSpansFollowContext(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := tracing.NewTracer(exporter)
+
+	remote, ok := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok)
+	ctx := tracing.ContextWithRemoteParent(context.Background(), remote)
+
+	ctx, server := tracer.Start(ctx, "POST /pullRequest/create", tracing.SpanKindServer)
+	serviceCtx, done := tracer.StartQuery(ctx, "service", "CreatePR")
+	_, query := tracer.StartQuery(serviceCtx, "pull_requests", "Exists")
+	query(errors.New("connection reset"))
+	done(nil)
+	server.SetAttributes(tracing.Int("http.response.status_code", 201))
+	server.End()
+	server.End()
+
+	require.Len(t, exporter.spans, 3)
+	exists, createPR, request := exporter.spans[0], exporter.spans[1], exporter.spans[2]
+
+	assert.Equal(t, remote.SpanID, request.ParentSpanID)
+	assert.Equal(t, request.Context.SpanID, createPR.ParentSpanID)
+	assert.Equal(t, createPR.Context.SpanID, exists.ParentSpanID)
+	for _, span := range exporter.spans {
+		assert.Equal(t, remote.TraceID, span.Context.TraceID)
+	}
+	assert.Equal(t, "pull_requests.Exists", exists.Name)
+	assert.Equal(t, "connection reset", exists.Error)
+	assert.Empty(t, createPR.Error)
+	assert.Equal(t, tracing.SpanKindServer, request.Kind)
+	assert.Equal(t, []tracing.Attribute{tracing.Int("http.response.status_code", 201)}, request.Attributes)
+
+	_, root := tracer.Start(context.Background(), "root", tracing.SpanKindInternal)
+	assert.NotEqual(t, remote.TraceID, root.SpanContext().TraceID)
+	assert.True(t, root.SpanContext().Sampled)
+}
+
+func TestUnsampledParentIsNotExported(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := tracing.NewTracer(exporter)
+
+	remote, ok := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.True(t, ok)
+	_, span := tracer.Start(tracing.ContextWithRemoteParent(context.Background(), remote), "request", tracing.SpanKindServer)
+	span.End()
+
+	assert.Empty(t, exporter.spans)
+}
+
+func TestDisabledTracer(t *testing.T) {
+	tracer := tracing.NewTracer(tracing.NopExporter{})
+	assert.False(t, tracer.Enabled())
+
+	ctx, span := tracer.Start(context.Background(), "request", tracing.SpanKindServer)
+	assert.Nil(t, span)
+	assert.Nil(t, tracing.SpanFromContext(ctx))
+	span.SetAttributes(tracing.String("key", "value"))
+	span.RecordError(errors.New("ignored"))
+	span.End()
+}
+
+func TestStdoutExporter(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := tracing.NewTracer(tracing.NewStdoutExporter(&buf))
+
+	ctx, parent := tracer.Start(context.Background(), "parent", tracing.SpanKindServer)
+	_, child := tracer.Start(ctx, "child", tracing.SpanKindInternal)
+	child.SetAttributes(tracing.String("team", "backend"))
+	child.End()
+
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	assert.Equal(t, "child", out["name"])
+	assert.Equal(t, "internal", out["kind"])
+	assert.Equal(t, parent.SpanContext().TraceID.String(), out["trace_id"])
+	assert.Equal(t, parent.SpanContext().SpanID.String(), out["parent_span_id"])
+	assert.Equal(t, map[string]any{"team": "backend"}, out["attributes"])
+}
+
+func TestOTLPExporter(t *testing.T) {
+	received := make(chan map[string]any, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		received <- body
+	}))
+	defer collector.Close()
+
+	exporter := tracing.NewOTLPExporter(collector.URL, "pr-review-service")
+	tracer := tracing.NewTracer(exporter)
+
+	_, span := tracer.Start(context.Background(), "GET /team/get", tracing.SpanKindServer)
+	span.SetAttributes(tracing.Int("http.response.status_code", 500))
+	span.RecordError(errors.New("boom"))
+	span.End()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, tracer.Shutdown(ctx))
+
+	body := <-received
+	resourceSpans := body["resourceSpans"].([]any)[0].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"attributes": []any{map[string]any{"key": "service.name", "value": map[string]any{"stringValue": "pr-review-service"}}},
+	}, resourceSpans["resource"])
+
+	spans := resourceSpans["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)
+	require.Len(t, spans, 1)
+	exported := spans[0].(map[string]any)
+	assert.Equal(t, span.SpanContext().TraceID.String(), exported["traceId"])
+	assert.Equal(t, "GET /team/get", exported["name"])
+	assert.Equal(t, float64(2), exported["kind"])
+	assert.Equal(t, map[string]any{"code": float64(2), "message": "boom"}, exported["status"])
+	assert.Equal(t, []any{map[string]any{
+		"key": "http.response.status_code", "value": map[string]any{"intValue": "500"},
+	}}, exported["attributes"])
+}
@@ -13,6 +13,7 @@ type Config struct {
 	TotalRequests int
 	Concurrency   int
 	Timeout       time.Duration
+	APIToken      string
 }
 
 func LoadConfig() *Config {
@@ -27,6 +28,7 @@ func LoadConfig() *Config {
 		TotalRequests: totalRequests,
 		Concurrency:   concurrency,
 		Timeout:       timeout,
+		APIToken:      getEnv("API_TOKEN", ""),
 	}
 }
 
@@ -140,7 +140,7 @@ func generateRequest(id int, baseURL string) (string, Request, int) {
 		}
 		return "health_check", Request{
 			Method:   "GET",
-			URL:      baseURL + "/health",
+			URL:      baseURL + "/livez",
 			Body:     nil,
 			Expected: http.StatusOK,
 		}, http.StatusOK
@@ -161,7 +161,7 @@ func generateRequest(id int, baseURL string) (string, Request, int) {
 		}
 		return "health_check", Request{
 			Method:   "GET",
-			URL:      baseURL + "/health",
+			URL:      baseURL + "/livez",
 			Body:     nil,
 			Expected: http.StatusOK,
 		}, http.StatusOK
@@ -169,7 +169,7 @@ func generateRequest(id int, baseURL string) (string, Request, int) {
 	case 3:
 		return "health_check", Request{
 			Method:   "GET",
-			URL:      baseURL + "/health",
+			URL:      baseURL + "/livez",
 			Body:     nil,
 			Expected: http.StatusOK,
 		}, http.StatusOK
@@ -186,7 +186,7 @@ func generateRequest(id int, baseURL string) (string, Request, int) {
 		}
 		return "health_check", Request{
 			Method:   "GET",
-			URL:      baseURL + "/health",
+			URL:      baseURL + "/livez",
 			Body:     nil,
 			Expected: http.StatusOK,
 		}, http.StatusOK
@@ -209,7 +209,7 @@ func generateRequest(id int, baseURL string) (string, Request, int) {
 		}
 		return "health_check", Request{
 			Method:   "GET",
-			URL:      baseURL + "/health",
+			URL:      baseURL + "/livez",
 			Body:     nil,
 			Expected: http.StatusOK,
 		}, http.StatusOK
@@ -242,7 +242,7 @@ func generateRequest(id int, baseURL string) (string, Request, int) {
 
 	return "health_check", Request{
 		Method:   "GET",
-		URL:      baseURL + "/health",
+		URL:      baseURL + "/livez",
 		Body:     nil,
 		Expected: http.StatusOK,
 	}, http.StatusOK
@@ -273,6 +273,9 @@ func sendRequest(cfg *Config, request Request, expectedStatus int) error {
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
+	if cfg.APIToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+cfg.APIToken)
+	}
 
 	resp, err := client.Do(httpReq)
 	if err != nil {
@@ -0,0 +1,3 @@
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS fallback_reviewers;
+
+DROP TABLE IF EXISTS team_fallbacks;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS team_fallbacks (
+    team_name VARCHAR(255) NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    fallback_team_name VARCHAR(255) NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    priority INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (team_name, fallback_team_name)
+);
+
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS fallback_reviewers JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_team_fallbacks_priority ON team_fallbacks(team_name, priority);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS reviews;
+
+ALTER TABLE teams DROP COLUMN IF EXISTS required_approvals;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS reviewer_assignments (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id),
+    action VARCHAR(50) NOT NULL,
+    operation VARCHAR(50) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviewer_assignments_pr ON reviewer_assignments(pull_request_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_reviewer_assignments_user ON reviewer_assignments(user_id);
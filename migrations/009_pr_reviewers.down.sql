@@ -0,0 +1,12 @@
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS assigned_reviewers JSONB;
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS fallback_reviewers JSONB;
+
+UPDATE pull_requests p
+SET assigned_reviewers = COALESCE(
+        (SELECT jsonb_agg(r.user_id ORDER BY r.slot) FROM pr_reviewers r WHERE r.pull_request_id = p.pull_request_id),
+        '[]'::jsonb),
+    fallback_reviewers = (
+        SELECT jsonb_agg(r.user_id ORDER BY r.slot) FROM pr_reviewers r
+        WHERE r.pull_request_id = p.pull_request_id AND r.is_fallback);
+
+DROP TABLE IF EXISTS pr_reviewers;
@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS pr_reviewers (
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    slot INTEGER NOT NULL,
+    is_fallback BOOLEAN NOT NULL DEFAULT FALSE,
+    assigned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (pull_request_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_user_id ON pr_reviewers(user_id);
+
+DO $$
+DECLARE
+    orphaned TEXT;
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'pull_requests' AND column_name = 'assigned_reviewers'
+    ) THEN
+        -- Refuse to drop assigned_reviewers while it names users that do not
+        -- exist: they cannot be copied into pr_reviewers and would be lost.
+        SELECT string_agg(DISTINCT p.pull_request_id || ':' || r.user_id, ', ')
+        INTO orphaned
+        FROM pull_requests p
+        CROSS JOIN LATERAL jsonb_array_elements_text(p.assigned_reviewers) AS r(user_id)
+        WHERE jsonb_typeof(p.assigned_reviewers) = 'array'
+          AND NOT EXISTS (SELECT 1 FROM users u WHERE u.user_id = r.user_id);
+
+        IF orphaned IS NOT NULL THEN
+            RAISE EXCEPTION 'pull_requests.assigned_reviewers references missing users (pull_request_id:user_id): %', orphaned
+                USING HINT = 'Create the missing users or remove them from assigned_reviewers, then run the migrations again.';
+        END IF;
+
+        INSERT INTO pr_reviewers (pull_request_id, user_id, slot, is_fallback, assigned_at)
+        SELECT p.pull_request_id,
+               r.user_id,
+               r.slot - 1,
+               COALESCE(jsonb_typeof(p.fallback_reviewers) = 'array' AND p.fallback_reviewers @> jsonb_build_array(r.user_id), FALSE),
+               COALESCE(p.created_at, CURRENT_TIMESTAMP)
+        FROM pull_requests p
+        CROSS JOIN LATERAL jsonb_array_elements_text(p.assigned_reviewers) WITH ORDINALITY AS r(user_id, slot)
+        WHERE jsonb_typeof(p.assigned_reviewers) = 'array'
+        ON CONFLICT DO NOTHING;
+    END IF;
+END $$;
+
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS assigned_reviewers;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS fallback_reviewers;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_teams_archived_at;
+
+ALTER TABLE teams DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,3 @@
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS merged_by;
+
+DROP TABLE IF EXISTS api_tokens;
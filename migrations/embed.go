@@ -0,0 +1,9 @@
+package migrations
+
+import "embed"
+
+// FS holds the versioned SQL migrations, named NNN_description.up.sql and
+// NNN_description.down.sql.
+//
+//go:embed *.sql
+var FS embed.FS